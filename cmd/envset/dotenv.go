@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readDotEnv reads KEY=VALUE pairs from a .env file. Blank lines and comments are skipped,
+// values may be quoted, and an optional "export " prefix is allowed.
+func readDotEnv(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, val, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+		}
+
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+
+		switch {
+		case strings.HasPrefix(val, `"`):
+			if val, err = strconv.Unquote(val); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+			}
+		case strings.HasPrefix(val, "'") && strings.HasSuffix(val, "'") && len(val) > 1:
+			val = val[1 : len(val)-1]
+		default:
+			// Unquoted values may have trailing comments
+			if i := strings.Index(val, " #"); i >= 0 {
+				val = strings.TrimSpace(val[:i])
+			}
+		}
+
+		values[key] = val
+	}
+
+	return values, scanner.Err()
+}
@@ -0,0 +1,1053 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/types"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dmytro-vovk/envset"
+)
+
+var errNotSupported = errors.New("not supported by the generator")
+
+// goType is a field type resolved from the package source.
+type goType struct {
+	// expr is the type expression, e.g. Level, *int, []time.Duration
+	expr string
+	kind reflect.Kind
+	// elem is the element of pointers and slices
+	elem *goType
+	// parser is the expression of the custom parser function of the type
+	parser string
+}
+
+// generator writes a reflection-free loader of a struct type.
+type generator struct {
+	pkg      *pkg
+	cfg      config
+	typeName string
+	// keys are the resolved keys by the Go path of the field
+	keys map[string]string
+	// parsers map type expressions to parser function expressions
+	parsers map[string]string
+	// imports are the import paths used by the generated code
+	imports map[string]bool
+	// used are the variables of the loader function in use
+	used    map[string]bool
+	funcs   bytes.Buffer
+	body    bytes.Buffer
+	samples []sample
+}
+
+// sample is a key with a plausible value, used by the generated test.
+// Values of custom types are only known from default and example tags.
+type sample struct {
+	key, value string
+	known      bool
+}
+
+var defaultBooleans = [2][]string{
+	{"0", "f", "false", "n", "nay", "no"},
+	{"1", "t", "true", "y", "yay", "yes"},
+}
+
+// generate writes the loader of the struct model, and its test if requested.
+func generate(p *pkg, model *structModel, cfg config) error {
+	g := &generator{
+		pkg:      p,
+		cfg:      cfg,
+		typeName: cfg.typeName,
+		parsers:  make(map[string]string),
+		imports:  map[string]bool{"fmt": true, "github.com/dmytro-vovk/envset": true},
+		used:     make(map[string]bool),
+	}
+
+	keys, err := resolveKeys(model, cfg)
+	if err != nil {
+		return err
+	}
+
+	g.keys = keys
+
+	for _, parser := range cfg.parsers {
+		typeName, fn, ok := strings.Cut(parser, "=")
+		if !ok {
+			return fmt.Errorf("invalid -parser %q, expected Type=Func", parser)
+		}
+
+		if err := g.useExpr(typeName); err != nil {
+			return err
+		}
+
+		if err := g.useExpr(fn); err != nil {
+			return err
+		}
+
+		g.parsers[typeName] = fn
+	}
+
+	if err := g.structFields(model, "v.", nil, false, ""); err != nil {
+		return err
+	}
+
+	output := cfg.output
+	if output == "" {
+		output = filepath.Join(p.dir, strings.ToLower(cfg.typeName)+"_envset.go")
+	}
+
+	if err := writeSource(output, g.loader); err != nil {
+		return err
+	}
+
+	if !cfg.test {
+		return nil
+	}
+
+	return writeSource(strings.TrimSuffix(output, ".go")+"_test.go", g.test)
+}
+
+// writeSource formats the source produced by the function and writes it to the file.
+func writeSource(path string, fn func(w io.Writer)) error {
+	var buf bytes.Buffer
+
+	fn(&buf)
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting %s: %w", path, err)
+	}
+
+	return os.WriteFile(path, src, 0o644)
+}
+
+// useExpr records imports of package qualifiers used in the expression.
+func (g *generator) useExpr(expr string) error {
+	paths, err := g.exprImports(expr)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		g.imports[path] = true
+	}
+
+	return nil
+}
+
+// exprImports lists import paths of package qualifiers used in the expression.
+func (g *generator) exprImports(expr string) ([]string, error) {
+	parsed, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", expr, err)
+	}
+
+	var paths []string
+
+	ast.Inspect(parsed, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok {
+				if importPath, ok := g.pkg.imports[x.Name]; ok {
+					paths = append(paths, importPath)
+				}
+			}
+		}
+
+		return true
+	})
+
+	return paths, nil
+}
+
+func (g *generator) use(names ...string) {
+	for _, name := range names {
+		g.used[name] = true
+	}
+}
+
+// loader writes the loader function and the parsers of field values.
+func (g *generator) loader(w io.Writer) {
+	fprintf(w, "// Code generated by envset gen; DO NOT EDIT.\n\npackage %s\n\n", g.pkg.name)
+	writeImports(w, g.imports)
+
+	fprintf(w, "// Load%[1]s populates %[1]s from the source, the process environment if it is nil,\n", g.typeName)
+	fprintf(w, "// the same way as envset.Set does, without reflection.\n")
+	fprintf(w, "func Load%[1]s(src envset.Source) (%[1]s, error) {\n", g.typeName)
+	fprintf(w, "var v %s\n\n", g.typeName)
+
+	if g.used["source"] {
+		fprintf(w, "if src == nil {\nsrc = envset.Environment()\n}\n\n")
+		fprintf(w, "source := fmt.Sprintf(\"%%T\", src)\n")
+		fprintf(w, "if named, ok := src.(envset.NamedSource); ok {\nsource = named.SourceName()\n}\n\n")
+	}
+
+	var vars []string
+
+	for _, name := range []string{"val", "from", "ok", "err"} {
+		if g.used[name] {
+			vars = append(vars, name)
+		}
+	}
+
+	if len(vars) > 0 {
+		fprintf(w, "var (\n")
+
+		for _, name := range vars {
+			fprintf(w, "%s %s\n", name, map[string]string{"val": "string", "from": "string", "ok": "bool", "err": "error"}[name])
+		}
+
+		fprintf(w, ")\n\n")
+	}
+
+	_, _ = w.Write(g.body.Bytes())
+	fprintf(w, "return v, nil\n}\n")
+	_, _ = w.Write(g.funcs.Bytes())
+}
+
+// test writes the test comparing the loader with envset.Set.
+func (g *generator) test(w io.Writer) {
+	imports := map[string]bool{
+		"fmt":                           true,
+		"reflect":                       true,
+		"testing":                       true,
+		"github.com/dmytro-vovk/envset": true,
+	}
+
+	for _, parser := range g.parsers {
+		paths, _ := g.exprImports(parser)
+		for _, path := range paths {
+			imports[path] = true
+		}
+	}
+
+	fprintf(w, "// Code generated by envset gen; DO NOT EDIT.\n\npackage %s\n\n", g.pkg.name)
+	writeImports(w, imports)
+
+	values := make(map[string]string, len(g.samples))
+	for _, s := range g.samples {
+		if s.known {
+			values[s.key] = s.value
+		}
+	}
+
+	cases := []struct {
+		name   string
+		values map[string]string
+	}{
+		{name: "empty"},
+		{name: "values", values: values},
+	}
+
+	for _, s := range g.samples {
+		for _, val := range []string{"invalid", ""} {
+			modified := make(map[string]string, len(values))
+			for key, value := range values {
+				modified[key] = value
+			}
+
+			modified[s.key] = val
+			cases = append(cases, struct {
+				name   string
+				values map[string]string
+			}{name: s.key + "=" + val, values: modified})
+		}
+	}
+
+	fprintf(w, "func TestLoad%s(t *testing.T) {\n", g.typeName)
+	fprintf(w, "for _, tc := range []struct {\nname string\nsrc envset.MapSource\n}{\n")
+
+	for _, c := range cases {
+		keys := make([]string, 0, len(c.values))
+		for key := range c.values {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		fprintf(w, "{name: %q, src: envset.MapSource{", c.name)
+
+		for _, key := range keys {
+			fprintf(w, "\n%q: %q,", key, c.values[key])
+		}
+
+		if len(keys) > 0 {
+			fprintf(w, "\n")
+		}
+
+		fprintf(w, "}},\n")
+	}
+
+	fprintf(w, "} {\nt.Run(tc.name, func(t *testing.T) {\n")
+	fprintf(w, "got, err := Load%s(tc.src)\n\n", g.typeName)
+	fprintf(w, "var want %s\n\n", g.typeName)
+	fprintf(w, "wantErr := envset.Set(&want,\nenvset.WithSource(tc.src),\n")
+	fprintf(w, "envset.WithEnvTag(%q),\nenvset.WithDefaultTag(%q),\nenvset.WithSliceSeparator(%q),\n",
+		g.cfg.envTag, g.cfg.defaultTag, g.cfg.separator)
+
+	for _, v := range g.cfg.vars {
+		name, value, _ := strings.Cut(v, "=")
+		fprintf(w, "envset.WithKeyVar(%q, %q),\n", name, value)
+	}
+
+	typeNames := make([]string, 0, len(g.parsers))
+	for typeName := range g.parsers {
+		typeNames = append(typeNames, typeName)
+	}
+
+	sort.Strings(typeNames)
+
+	for _, typeName := range typeNames {
+		fprintf(w, "envset.WithTypeParser(%s),\n", g.parsers[typeName])
+	}
+
+	fprintf(w, ")\n\n")
+	fprintf(w, "if fmt.Sprint(err) != fmt.Sprint(wantErr) {\n")
+	fprintf(w, "t.Fatalf(\"Load%s() error = %%v, envset.Set() error = %%v\", err, wantErr)\n}\n\n", g.typeName)
+	fprintf(w, "if wantErr == nil && !reflect.DeepEqual(got, want) {\n")
+	fprintf(w, "t.Errorf(\"Load%s() = %%+v, envset.Set() = %%+v\", got, want)\n}\n", g.typeName)
+	fprintf(w, "})\n}\n}\n")
+}
+
+// writeImports writes the import declaration, standard packages first.
+func writeImports(w io.Writer, imports map[string]bool) {
+	var std, other []string
+
+	for path := range imports {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+
+	sort.Strings(std)
+	sort.Strings(other)
+
+	fprintf(w, "import (\n")
+
+	for _, path := range std {
+		fprintf(w, "%q\n", path)
+	}
+
+	if len(std) > 0 && len(other) > 0 {
+		fprintf(w, "\n")
+	}
+
+	for _, path := range other {
+		fprintf(w, "%q\n", path)
+	}
+
+	fprintf(w, ")\n\n")
+}
+
+// resolveKeys resolves the keys of the model fields by their Go paths, the same way Set does:
+// the struct built from the model is populated from an empty source with a key mapper recording the keys.
+func resolveKeys(model *structModel, cfg config) (map[string]string, error) {
+	options, err := cfg.options()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]string)
+
+	options = append(options,
+		envset.WithSource(envset.MapSource{}),
+		envset.WithAllErrors(),
+		envset.WithKeyMapper(func(fieldPath []string, key string) string {
+			keys[strings.Join(fieldPath, ".")] = key
+			return key
+		}),
+	)
+
+	err = envset.SetValue(reflect.New(model.reflectType()), options...)
+
+	var errs envset.Errors
+	if !errors.As(err, &errs) && err != nil {
+		errs = envset.Errors{err}
+	}
+
+	// Missing values are expected, errors without a key are the failures to resolve it
+	for _, err := range errs {
+		var fieldErr *envset.FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Key == "" {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+// structTarget returns the struct type expression of a struct or pointer to struct field.
+func structTarget(expr ast.Expr) ast.Expr {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		return star.X
+	}
+
+	return expr
+}
+
+func fprintf(w io.Writer, format string, args ...any) {
+	_, _ = fmt.Fprintf(w, format, args...)
+}
+
+// structFields writes loading of the struct fields accessed with the prefix, e.g. "v.DB.",
+// of the struct held by value or by pointer. The struct embedded in another one
+// comes with the target of the embedding struct, e.g. "&v".
+func (g *generator) structFields(model *structModel, access string, path []string, pointer bool, embeddedIn string) error {
+	// Validate is called for the struct after its fields are populated
+	target := "&" + strings.TrimSuffix(access, ".")
+	if pointer {
+		target = strings.TrimSuffix(access, ".")
+	}
+
+	for _, f := range model.fields {
+		if err := g.field(f, access, path, target); err != nil {
+			return fmt.Errorf("field %s: %w", strings.Join(append(path, f.name), "."), err)
+		}
+	}
+
+	// Validate of the embedded struct is promoted or shadowed, then it is called with the embedding struct
+	if embeddedIn != "" {
+		fprintf(&g.body, "if _, ok := any(%s).(envset.Validator); !ok {\n", embeddedIn)
+	}
+
+	fprintf(&g.body, "if validator, ok := any(%s).(envset.Validator); ok {\n", target)
+	fprintf(&g.body, "if err := validator.Validate(); err != nil {\n")
+
+	if len(path) == 0 {
+		fprintf(&g.body, "return %s{}, err\n", g.typeName)
+	} else {
+		fprintf(&g.body, "return %s{}, fmt.Errorf(\"%%s: %%w\", %q, err)\n", g.typeName, strings.Join(path, "."))
+	}
+
+	fprintf(&g.body, "}\n}\n")
+
+	if embeddedIn != "" {
+		fprintf(&g.body, "}\n")
+	}
+
+	fprintf(&g.body, "\n")
+
+	return nil
+}
+
+func (g *generator) field(f fieldModel, access string, path []string, parent string) error {
+	_, custom := g.parsers[f.typeName]
+
+	switch {
+	case f.kind == structField && !custom:
+		// Field types of imported structs are resolved in their own package, not in the generated file
+		if _, ok := structTarget(f.expr).(*ast.SelectorExpr); ok {
+			return fmt.Errorf("struct types declared outside the package are %w", errNotSupported)
+		}
+
+		if f.pointer {
+			star, ok := f.expr.(*ast.StarExpr)
+			if !ok {
+				return fmt.Errorf("embedded pointer %w", errNotSupported)
+			}
+
+			if _, ok := star.X.(*ast.StructType); ok {
+				return fmt.Errorf("pointer to anonymous struct %w", errNotSupported)
+			}
+
+			fprintf(&g.body, "%s%s = new(%s)\n\n", access, f.name, types.ExprString(star.X))
+		}
+
+		embeddedIn := ""
+		if f.embedded {
+			embeddedIn = parent
+		}
+
+		return g.structFields(f.nested, access+f.name+".", append(path[:len(path):len(path)], f.name), f.pointer, embeddedIn)
+	case f.kind == sliceField || f.kind == mapField:
+		if _, ok := f.tag.Lookup(g.cfg.envTag); ok {
+			return fmt.Errorf("slices and maps of structs are %w", errNotSupported)
+		}
+
+		return nil
+	}
+
+	tagged, ok := f.tag.Lookup(g.cfg.envTag)
+	if !ok {
+		return nil
+	}
+
+	for _, name := range []string{"expand", "required_if", "required_with", "oneof_group", "deprecated"} {
+		if _, ok := f.tag.Lookup(name); ok {
+			return fmt.Errorf("%s tag is %w", name, errNotSupported)
+		}
+	}
+
+	fieldPath := strings.Join(append(path[:len(path):len(path)], f.name), ".")
+	key := g.keys[fieldPath]
+
+	t, err := g.resolve(f.expr, f.typeName)
+	if err != nil {
+		return err
+	}
+
+	parse := t.parser
+	if parse == "" {
+		// Path segments are separated, so that DB.Port and DBPort fields get distinct names,
+		// and suffixes are lowercase, so that they do not clash with exported field names
+		name := "parse" + g.typeName + "_" + strings.Join(append(path[:len(path):len(path)], f.name), "_")
+		if parse, err = g.parseFunc(name, t, f.tag); err != nil {
+			return err
+		}
+	}
+
+	def, hasDefault := f.tag.Lookup(g.cfg.defaultTag)
+	optional := strings.HasSuffix(tagged, ",omitempty")
+	secret, _ := strconv.ParseBool(f.tag.Get("secret"))
+
+	value, known := sampleValue(t, f.tag, def, hasDefault)
+	g.samples = append(g.samples, sample{key: key, value: value, known: known})
+
+	g.use("source", "val", "from", "err")
+	fprintf(&g.body, "// %s\n", fieldPath)
+	fprintf(&g.body, "from = source\n")
+
+	switch {
+	case hasDefault:
+		g.use("ok")
+		fprintf(&g.body, "if val, ok = src.LookupEnv(%q); !ok {\n", key)
+		fprintf(&g.body, "val, from = %q, envset.SourceDefault\n}\n\n", def)
+	case optional:
+		fprintf(&g.body, "val, _ = src.LookupEnv(%q)\n\n", key)
+	default:
+		g.use("ok")
+		fprintf(&g.body, "if val, ok = src.LookupEnv(%q); !ok {\n", key)
+		fprintf(&g.body, "return %s{}, &envset.FieldError{Path: %q, Key: %q, Err: envset.NewMissingValueError(%q)}\n}\n\n",
+			g.typeName, fieldPath, key, key)
+	}
+
+	valueError := func(err string) string {
+		if secret {
+			return fmt.Sprintf("&envset.FieldError{Path: %q, Key: %q, Redacted: true, Source: from, Err: envset.Redact(%s)}",
+				fieldPath, key, err)
+		}
+
+		return fmt.Sprintf("&envset.FieldError{Path: %q, Key: %q, Value: val, Source: from, Err: %s}", fieldPath, key, err)
+	}
+
+	if optional {
+		fprintf(&g.body, "if val != \"\" {\n")
+	} else if t.parser != "" {
+		fprintf(&g.body, "if val == \"\" {\nreturn %s{}, %s\n}\n\n", g.typeName, valueError(fmt.Sprintf("envset.NewMissingValueError(%q)", key)))
+	}
+
+	fprintf(&g.body, "if %s%s, err = %s(val); err != nil {\nreturn %s{}, %s\n}\n", access, f.name, parse, g.typeName, valueError("err"))
+
+	if optional {
+		fprintf(&g.body, "}\n")
+	}
+
+	fprintf(&g.body, "\n")
+
+	return nil
+}
+
+// resolve resolves the type expression to a kind supported by envset.Set.
+func (g *generator) resolve(expr ast.Expr, exprString string) (*goType, error) {
+	if parser, ok := g.parsers[exprString]; ok {
+		if err := g.useExpr(exprString); err != nil {
+			return nil, err
+		}
+
+		return &goType{expr: exprString, parser: parser}, nil
+	}
+
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		elem, err := g.resolve(t.X, types.ExprString(t.X))
+		if err != nil {
+			return nil, err
+		}
+
+		if elem.kind == reflect.Pointer || elem.parser != "" {
+			return nil, fmt.Errorf("type %s is %w", exprString, errNotSupported)
+		}
+
+		return &goType{expr: exprString, kind: reflect.Pointer, elem: elem}, nil
+	case *ast.ArrayType:
+		if t.Len != nil {
+			break
+		}
+
+		elem, err := g.resolve(t.Elt, types.ExprString(t.Elt))
+		if err != nil {
+			return nil, err
+		}
+
+		if elem.kind == reflect.Slice || elem.kind == reflect.Pointer && elem.elem.kind == reflect.Slice {
+			return nil, fmt.Errorf("unsupported slice elements type: %s", elem.expr)
+		}
+
+		return &goType{expr: exprString, kind: reflect.Slice, elem: elem}, nil
+	case *ast.Ident:
+		if basic, ok := basicTypes[t.Name]; ok {
+			return &goType{expr: exprString, kind: basic.Kind()}, nil
+		}
+
+		if underlying, ok := g.pkg.types[t.Name]; ok && !g.pkg.resolving[t.Name] {
+			g.pkg.resolving[t.Name] = true
+			defer delete(g.pkg.resolving, t.Name)
+
+			u, err := g.resolve(underlying, types.ExprString(underlying))
+			if err != nil {
+				return nil, err
+			}
+
+			if u.kind == reflect.Pointer || u.parser != "" {
+				return nil, fmt.Errorf("type %s is %w", exprString, errNotSupported)
+			}
+
+			return &goType{expr: exprString, kind: u.kind, elem: u.elem}, nil
+		}
+	case *ast.SelectorExpr:
+		return nil, fmt.Errorf("type %s needs a parser, use -parser %s=Func", exprString, exprString)
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", exprString)
+}
+
+// parseFunc writes the function parsing values of the type with constraints of the tag
+// and returns its name.
+func (g *generator) parseFunc(name string, t *goType, tag reflect.StructTag) (string, error) {
+	var body bytes.Buffer
+
+	result, err := g.parseBody(&body, name, t, tag)
+	if err != nil {
+		return "", err
+	}
+
+	if err := g.useExpr(t.expr); err != nil {
+		return "", err
+	}
+
+	fprintf(&g.funcs, "\nfunc %s(val string) (v %s, err error) {\n", name, t.expr)
+	_, _ = g.funcs.Write(body.Bytes())
+	fprintf(&g.funcs, "return %s, nil\n}\n", result)
+
+	return name, nil
+}
+
+// parseBody writes statements parsing val into the type, the same way as envset.Set does,
+// and returns the expression of the result.
+func (g *generator) parseBody(w io.Writer, name string, t *goType, tag reflect.StructTag) (string, error) {
+	switch t.kind {
+	case reflect.Pointer:
+		result, err := g.parseBody(w, name, t.elem, tag)
+		if err != nil {
+			return "", err
+		}
+
+		fprintf(w, "x := %s\n\n", result)
+
+		return "&x", nil
+	case reflect.Bool:
+		g.imports["strings"] = true
+
+		fprintf(w, "var b bool\n\nswitch strings.ToLower(val) {\n")
+		fprintf(w, "case %s:\nb = true\n", quoteList(defaultBooleans[1]))
+		fprintf(w, "case %s:\n", quoteList(defaultBooleans[0]))
+		fprintf(w, "default:\nreturn v, errors.New(\"invalid bool value \" + val)\n}\n\n")
+		g.imports["errors"] = true
+
+		return convert(t, "b"), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		g.imports["strconv"] = true
+
+		fprintf(w, "n, err := strconv.Atoi(val)\nif err != nil {\nreturn v, err\n}\n\n")
+
+		if err := g.numericEnum(w, tag, "strconv.ParseFloat(val, 64)"); err != nil {
+			return "", err
+		}
+
+		if t.kind == reflect.Int {
+			fprintf(w, "i := n\n\n")
+		} else {
+			fprintf(w, "i := %s(n)\n\n", t.kind)
+		}
+
+		if err := g.limits(w, t.kind, tag); err != nil {
+			return "", err
+		}
+
+		return convert(t, "i"), nil
+	case reflect.Float32, reflect.Float64:
+		g.imports["strconv"] = true
+
+		fprintf(w, "f, err := strconv.ParseFloat(val, 64)\nif err != nil {\nreturn v, err\n}\n\n")
+
+		if err := g.numericEnum(w, tag, ""); err != nil {
+			return "", err
+		}
+
+		fprintf(w, "i := %s(f)\n\n", t.kind)
+
+		if err := g.limits(w, t.kind, tag); err != nil {
+			return "", err
+		}
+
+		return convert(t, "i"), nil
+	case reflect.String:
+		if err := g.lengths(w, tag, "utf8.RuneCountInString(val)"); err != nil {
+			return "", err
+		}
+
+		if pattern, ok := tag.Lookup("pattern"); ok {
+			patternVar, err := g.pattern(name, pattern)
+			if err != nil {
+				return "", err
+			}
+
+			fprintf(w, "if !%s.MatchString(val) {\nreturn v, envset.ErrInvalidValue\n}\n\n", patternVar)
+		}
+
+		g.stringEnum(w, tag)
+
+		return convert(t, "val"), nil
+	case reflect.Slice:
+		return g.parseSlice(w, name, t, tag)
+	}
+
+	return "", fmt.Errorf("unsupported type %s", t.expr)
+}
+
+func (g *generator) parseSlice(w io.Writer, name string, t *goType, tag reflect.StructTag) (string, error) {
+	g.imports["strings"] = true
+
+	fprintf(w, "parts := strings.Split(val, %q)\n\n", g.cfg.separator)
+
+	if err := g.lengths(w, tag, "len(parts)"); err != nil {
+		return "", err
+	}
+
+	parse := t.elem.parser
+	if parse == "" {
+		var err error
+		if parse, err = g.parseFunc(name+"_elem", t.elem, elemTag(tag)); err != nil {
+			return "", err
+		}
+	} else if err := g.useExpr(t.elem.expr); err != nil {
+		return "", err
+	}
+
+	fprintf(w, "elems := make([]%s, len(parts))\n\nfor i, part := range parts {\n", t.elem.expr)
+
+	if pattern, ok := tag.Lookup("pattern"); ok {
+		patternVar, err := g.pattern(name, pattern)
+		if err != nil {
+			return "", err
+		}
+
+		fprintf(w, "if !%s.MatchString(part) {\n", patternVar)
+		fprintf(w, "return v, fmt.Errorf(\"element %%d: value %%s does not match pattern %%s\", i, part, %s)\n}\n\n", patternVar)
+	}
+
+	fprintf(w, "if elems[i], err = %s(part); err != nil {\nreturn v, fmt.Errorf(\"element %%d: %%w\", i, err)\n}\n}\n\n", parse)
+
+	if unique, _ := strconv.ParseBool(tag.Get("unique")); unique {
+		key, elem := t.elem.expr, "elem"
+		if t.elem.kind == reflect.Pointer {
+			key, elem = t.elem.elem.expr, "*elem"
+		}
+
+		fprintf(w, "seen := make(map[%s]int, len(elems))\n\nfor i, elem := range elems {\n", key)
+		fprintf(w, "if j, ok := seen[%s]; ok {\n", elem)
+		fprintf(w, "return v, fmt.Errorf(\"%%w: element %%d duplicates element %%d\", envset.ErrInvalidValue, i, j)\n}\n\n")
+		fprintf(w, "seen[%s] = i\n}\n\n", elem)
+	}
+
+	if t.expr == "[]"+t.elem.expr {
+		return "elems", nil
+	}
+
+	return t.expr + "(elems)", nil
+}
+
+// elemTag returns the tag of slice elements, without the constraints applied to the slice.
+func elemTag(tag reflect.StructTag) reflect.StructTag {
+	var parts []string
+
+	for _, name := range []string{"enum", "enum_fold", "min", "max", "unique"} {
+		if val, ok := tag.Lookup(name); ok {
+			parts = append(parts, name+":"+strconv.Quote(val))
+		}
+	}
+
+	return reflect.StructTag(strings.Join(parts, " "))
+}
+
+// convert converts the value of the kind type to the type.
+func convert(t *goType, val string) string {
+	if t.expr == t.kind.String() {
+		return val
+	}
+
+	return t.expr + "(" + val + ")"
+}
+
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i := range values {
+		quoted[i] = strconv.Quote(values[i])
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// pattern declares the compiled pattern and returns its variable name.
+func (g *generator) pattern(name, pattern string) (string, error) {
+	if _, err := regexp.Compile(pattern); err != nil {
+		return "", err
+	}
+
+	g.imports["regexp"] = true
+	patternVar := name + "_pattern"
+
+	fprintf(&g.funcs, "\nvar %s = regexp.MustCompile(%q)\n", patternVar, pattern)
+
+	return patternVar, nil
+}
+
+// lengths writes the checks of len, minlen and maxlen tags.
+func (g *generator) lengths(w io.Writer, tag reflect.StructTag, length string) error {
+	var checks []string
+
+	for _, limit := range []struct{ tag, op, message string }{
+		{tag: "len", op: "!=", message: "is not equal to the length"},
+		{tag: "minlen", op: "<", message: "is less than the minimal length"},
+		{tag: "maxlen", op: ">", message: "is greater than the maximal length"},
+	} {
+		value, ok := tag.Lookup(limit.tag)
+		if !ok {
+			continue
+		}
+
+		l, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("parsing %s value: %w", limit.tag, err)
+		}
+
+		checks = append(checks, fmt.Sprintf("case n %s %d:\nreturn v, fmt.Errorf(\"%%w: length %%d %s %%d\", envset.ErrInvalidValue, n, %d)\n",
+			limit.op, l, limit.message, l))
+	}
+
+	if len(checks) == 0 {
+		return nil
+	}
+
+	if strings.HasPrefix(length, "utf8.") {
+		g.imports["unicode/utf8"] = true
+	}
+
+	fprintf(w, "switch n := %s; {\n%s}\n\n", length, strings.Join(checks, ""))
+
+	return nil
+}
+
+// limits writes the checks of min and max tags of numbers held in the i variable.
+func (g *generator) limits(w io.Writer, kind reflect.Kind, tag reflect.StructTag) error {
+	for _, limit := range []struct{ tag, op, message string }{
+		{tag: "min", op: "<", message: "is less than the minimal value"},
+		{tag: "max", op: ">", message: "is greater than the maximal value"},
+	} {
+		value, ok := tag.Lookup(limit.tag)
+		if !ok {
+			continue
+		}
+
+		literal, err := numberLiteral(kind, value)
+		if err != nil {
+			return fmt.Errorf("parsing %s value: %w", limit.tag, err)
+		}
+
+		fprintf(w, "if i %s %s {\nreturn v, fmt.Errorf(\"value %%v %s %%v\", val, %s)\n}\n\n", limit.op, literal, limit.message, literal)
+	}
+
+	return nil
+}
+
+// numberLiteral returns the constant of the kind type with the value.
+func numberLiteral(kind reflect.Kind, value string) (string, error) {
+	switch kind {
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", err
+		}
+
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return "", fmt.Errorf("value %s is %w", value, errNotSupported)
+		}
+
+		// Rounded to float64 first, as envset.Set does
+		return fmt.Sprintf("%s(float64(%s))", kind, strconv.FormatFloat(f, 'g', -1, 64)), nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return "", err
+	}
+
+	bits := map[reflect.Kind]int{
+		reflect.Int: strconv.IntSize, reflect.Int8: 8, reflect.Int16: 16, reflect.Int32: 32, reflect.Int64: 64,
+		reflect.Uint: strconv.IntSize, reflect.Uint8: 8, reflect.Uint16: 16, reflect.Uint32: 32, reflect.Uint64: 64,
+	}[kind]
+
+	if kind >= reflect.Uint && kind <= reflect.Uint64 {
+		_, err = strconv.ParseUint(value, 10, bits)
+	} else {
+		_, err = strconv.ParseInt(value, 10, bits)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("value %s out of range of %s", value, kind)
+	}
+
+	return fmt.Sprintf("%s(%d)", kind, n), nil
+}
+
+// numericEnum writes the check of the enum tag of a number, compared as float
+// held in f, or parsed with the expression.
+func (g *generator) numericEnum(w io.Writer, tag reflect.StructTag, parse string) error {
+	enum, ok := tag.Lookup("enum")
+	if !ok {
+		return nil
+	}
+
+	g.imports["strconv"] = true
+	fold, _ := strconv.ParseBool(tag.Get("enum_fold"))
+	allowed := splitEnum(enum)
+
+	var (
+		conditions []string
+		numeric    bool
+	)
+
+	for _, a := range allowed {
+		if f, err := strconv.ParseFloat(a, 64); err == nil {
+			if math.IsInf(f, 0) || math.IsNaN(f) {
+				return fmt.Errorf("enum value %s is %w", a, errNotSupported)
+			}
+
+			conditions = append(conditions, "f == "+strconv.FormatFloat(f, 'g', -1, 64))
+			numeric = true
+		} else if fold {
+			g.imports["strings"] = true
+			conditions = append(conditions, fmt.Sprintf("strings.EqualFold(%q, val)", a))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("val == %q", a))
+		}
+	}
+
+	init := ""
+	if numeric && parse != "" {
+		init = "f, _ := " + parse + "; "
+	}
+
+	fprintf(w, "if %s!(%s) {\n", init, strings.Join(conditions, " || "))
+	fprintf(w, "return v, fmt.Errorf(\"%%w: %%s is not one of %%s\", envset.ErrInvalidValue, val, %q)\n}\n\n", strings.Join(allowed, ", "))
+
+	return nil
+}
+
+// stringEnum writes the check of the enum tag of a string, replacing val with the allowed value.
+func (g *generator) stringEnum(w io.Writer, tag reflect.StructTag) {
+	enum, ok := tag.Lookup("enum")
+	if !ok {
+		return
+	}
+
+	allowed := splitEnum(enum)
+	message := fmt.Sprintf("return v, fmt.Errorf(\"%%w: %%s is not one of %%s\", envset.ErrInvalidValue, val, %q)\n", strings.Join(allowed, ", "))
+
+	if fold, _ := strconv.ParseBool(tag.Get("enum_fold")); fold {
+		g.imports["strings"] = true
+
+		fprintf(w, "switch {\n")
+
+		for _, a := range allowed {
+			fprintf(w, "case strings.EqualFold(%q, val):\nval = %q\n", a, a)
+		}
+
+		fprintf(w, "default:\n%s}\n\n", message)
+
+		return
+	}
+
+	var distinct []string
+
+	for _, a := range allowed {
+		if !slices.Contains(distinct, a) {
+			distinct = append(distinct, a)
+		}
+	}
+
+	fprintf(w, "switch val {\ncase %s:\ndefault:\n%s}\n\n", quoteList(distinct), message)
+}
+
+func splitEnum(enum string) []string {
+	allowed := strings.Split(enum, ",")
+	for i := range allowed {
+		allowed[i] = strings.TrimSpace(allowed[i])
+	}
+
+	return allowed
+}
+
+// sampleValue returns a plausible value of the field for the generated test,
+// and whether it is known.
+func sampleValue(t *goType, tag reflect.StructTag, def string, hasDefault bool) (string, bool) {
+	if example, ok := tag.Lookup("example"); ok {
+		return example, true
+	}
+
+	if hasDefault {
+		return def, true
+	}
+
+	if enum, ok := tag.Lookup("enum"); ok {
+		return splitEnum(enum)[0], true
+	}
+
+	for t.kind == reflect.Pointer || t.kind == reflect.Slice {
+		t = t.elem
+	}
+
+	switch {
+	case t.parser != "":
+		return "", false
+	case t.kind == reflect.Bool:
+		return "true", true
+	case t.kind == reflect.String:
+		if n, err := strconv.Atoi(tag.Get("minlen")); err == nil {
+			return strings.Repeat("x", n), true
+		}
+
+		return "value", true
+	}
+
+	if min, ok := tag.Lookup("min"); ok {
+		return min, true
+	}
+
+	return "1", true
+}
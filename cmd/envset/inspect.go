@@ -0,0 +1,423 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type fieldKind int
+
+const (
+	scalarField fieldKind = iota
+	structField
+	sliceField
+	mapField
+)
+
+// structModel is a struct type as declared in the source.
+type structModel struct {
+	fields []fieldModel
+}
+
+// fieldModel is a struct field as declared in the source.
+type fieldModel struct {
+	name string
+	// typeName is the type expression as written in the source
+	typeName string
+	tag      reflect.StructTag
+	kind     fieldKind
+	// pointer is true for pointers to struct, and for slices and maps of pointers to struct
+	pointer bool
+	// scalar is the type used to check values of scalar fields
+	scalar reflect.Type
+	// nested is the struct of struct, slice and map fields
+	nested *structModel
+	// embedded is true for embedded fields
+	embedded bool
+	// expr is the type expression
+	expr ast.Expr
+}
+
+// pkg holds type declarations of a parsed package.
+type pkg struct {
+	name  string
+	dir   string
+	types map[string]ast.Expr
+	// qualifier is the package name prefixing its type names, empty for the inspected package
+	qualifier string
+	// imports maps import names of the package files to import paths
+	imports map[string]string
+	// opaque are type names to be checked as strings, having custom parsers at runtime,
+	// qualified by the package name for types of imported packages, e.g. url.URL
+	opaque map[string]bool
+	// resolving guards against recursive types
+	resolving map[string]bool
+	// deps are the imported packages by import path, shared by all the loaded packages
+	deps map[string]*pkg
+	// envTag is the tag holding variable names
+	envTag string
+}
+
+var errUnknownType = errors.New("type not found")
+
+// loadPackage parses non-test Go files of the package given by directory or import path.
+func loadPackage(path, envTag string) (*pkg, error) {
+	dir := path
+
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		bp, err := build.Import(path, ".", build.FindOnly)
+		if err != nil {
+			return nil, err
+		}
+
+		dir = bp.Dir
+	}
+
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &pkg{
+		opaque: make(map[string]bool),
+		deps:   make(map[string]*pkg),
+		envTag: envTag,
+	}
+
+	return p, p.parse(bp)
+}
+
+// parse reads type declarations and imports of the package files.
+func (p *pkg) parse(bp *build.Package) error {
+	p.name, p.dir = bp.Name, bp.Dir
+	p.types = make(map[string]ast.Expr)
+	p.imports = make(map[string]string)
+	p.resolving = make(map[string]bool)
+
+	fset := token.NewFileSet()
+
+	for _, name := range bp.GoFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(p.dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+
+		for _, spec := range file.Imports {
+			importPath, _ := strconv.Unquote(spec.Path.Value)
+
+			name := importPath[strings.LastIndex(importPath, "/")+1:]
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+
+			p.imports[name] = importPath
+		}
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					p.types[ts.Name.Name] = ts.Type
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// dep loads the imported package by the name it is imported with in the package files.
+func (p *pkg) dep(name string) (*pkg, error) {
+	importPath, ok := p.imports[name]
+	if !ok {
+		return nil, fmt.Errorf("package %s is not imported", name)
+	}
+
+	if dep, ok := p.deps[importPath]; ok {
+		return dep, nil
+	}
+
+	bp, err := build.Import(importPath, p.dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	dep := &pkg{qualifier: bp.Name, opaque: p.opaque, deps: p.deps, envTag: p.envTag}
+	if err := dep.parse(bp); err != nil {
+		return nil, err
+	}
+
+	p.deps[importPath] = dep
+
+	return dep, nil
+}
+
+// qualified returns the name of the package type as used by the -custom flag.
+func (p *pkg) qualified(name string) string {
+	if p.qualifier == "" {
+		return name
+	}
+
+	return p.qualifier + "." + name
+}
+
+// structModel builds the model of the named struct type.
+func (p *pkg) structModel(name string) (*structModel, error) {
+	expr, ok := p.types[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnknownType, name)
+	}
+
+	st, ok := expr.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a struct type", name)
+	}
+
+	if p.resolving[name] {
+		return nil, fmt.Errorf("recursive type %s is not supported", name)
+	}
+
+	p.resolving[name] = true
+	defer delete(p.resolving, name)
+
+	return p.structType(st)
+}
+
+func (p *pkg) structType(st *ast.StructType) (*structModel, error) {
+	m := &structModel{}
+
+	for _, f := range st.Fields.List {
+		var tag reflect.StructTag
+
+		if f.Tag != nil {
+			unquoted, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			tag = reflect.StructTag(unquoted)
+		}
+
+		names := make([]string, 0, len(f.Names))
+		for _, name := range f.Names {
+			names = append(names, name.Name)
+		}
+
+		// Embedded fields are named after their type
+		if len(names) == 0 {
+			names = append(names, embeddedName(f.Type))
+		}
+
+		for _, name := range names {
+			if !token.IsExported(name) {
+				continue
+			}
+
+			field, err := p.field(name, f.Type, tag)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", name, err)
+			}
+
+			field.embedded = len(f.Names) == 0
+
+			m.fields = append(m.fields, field)
+		}
+	}
+
+	return m, nil
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	default:
+		return ""
+	}
+}
+
+func (p *pkg) field(name string, expr ast.Expr, tag reflect.StructTag) (fieldModel, error) {
+	field := fieldModel{name: name, typeName: types.ExprString(expr), tag: tag, expr: expr}
+
+	target := expr
+	if star, ok := expr.(*ast.StarExpr); ok {
+		target = star.X
+	}
+
+	// Struct fields. Tagged fields of imported struct types, e.g. time.Time, have custom parsers
+	// at runtime, as Set ignores the tag of struct fields otherwise, so they are checked as scalars
+	_, imported := target.(*ast.SelectorExpr)
+	if _, tagged := tag.Lookup(p.envTag); !imported || !tagged {
+		if nested, err := p.nestedStruct(target); err != nil {
+			return field, err
+		} else if nested != nil {
+			field.kind, field.nested, field.pointer = structField, nested, target != expr
+			return field, nil
+		}
+	}
+
+	// Collections of structs
+	switch t := expr.(type) {
+	case *ast.ArrayType:
+		if elem, pointer, err := p.elemStruct(t.Elt); err != nil {
+			return field, err
+		} else if elem != nil && t.Len == nil {
+			field.kind, field.nested, field.pointer = sliceField, elem, pointer
+			return field, nil
+		}
+	case *ast.MapType:
+		if key, ok := t.Key.(*ast.Ident); ok && key.Name == "string" {
+			if elem, pointer, err := p.elemStruct(t.Value); err != nil {
+				return field, err
+			} else if elem != nil {
+				field.kind, field.nested, field.pointer = mapField, elem, pointer
+				return field, nil
+			}
+		}
+	}
+
+	field.kind, field.scalar = scalarField, p.scalar(expr)
+
+	return field, nil
+}
+
+// elemStruct returns the model of a collection element, if it is a struct or a pointer to struct.
+func (p *pkg) elemStruct(expr ast.Expr) (*structModel, bool, error) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		m, err := p.nestedStruct(star.X)
+		return m, true, err
+	}
+
+	m, err := p.nestedStruct(expr)
+
+	return m, false, err
+}
+
+// nestedStruct returns the model of a struct type expression, or nil if it is not a struct.
+func (p *pkg) nestedStruct(expr ast.Expr) (*structModel, error) {
+	switch t := expr.(type) {
+	case *ast.StructType:
+		return p.structType(t)
+	case *ast.Ident:
+		if _, ok := p.types[t.Name].(*ast.StructType); ok && !p.opaque[p.qualified(t.Name)] {
+			return p.structModel(t.Name)
+		}
+	case *ast.SelectorExpr:
+		return p.importedStruct(t)
+	}
+
+	return nil, nil
+}
+
+// importedStruct returns the model of a struct type declared in an imported package,
+// parsed from its source, or nil if it is not a struct or is listed in -custom.
+func (p *pkg) importedStruct(sel *ast.SelectorExpr) (*structModel, error) {
+	x, ok := sel.X.(*ast.Ident)
+	if !ok || p.opaque[types.ExprString(sel)] {
+		return nil, nil
+	}
+
+	dep, err := p.dep(x.Name)
+	if err != nil {
+		return nil, fmt.Errorf("type %s declared outside the package, use -custom: %w", types.ExprString(sel), err)
+	}
+
+	if _, ok := dep.types[sel.Sel.Name].(*ast.StructType); !ok {
+		return nil, nil
+	}
+
+	return dep.structModel(sel.Sel.Name)
+}
+
+var basicTypes = map[string]reflect.Type{
+	"string":  reflect.TypeOf(""),
+	"bool":    reflect.TypeOf(false),
+	"int":     reflect.TypeOf(int(0)),
+	"int8":    reflect.TypeOf(int8(0)),
+	"int16":   reflect.TypeOf(int16(0)),
+	"int32":   reflect.TypeOf(int32(0)),
+	"rune":    reflect.TypeOf(rune(0)),
+	"int64":   reflect.TypeOf(int64(0)),
+	"uint":    reflect.TypeOf(uint(0)),
+	"uint8":   reflect.TypeOf(uint8(0)),
+	"byte":    reflect.TypeOf(byte(0)),
+	"uint16":  reflect.TypeOf(uint16(0)),
+	"uint32":  reflect.TypeOf(uint32(0)),
+	"uint64":  reflect.TypeOf(uint64(0)),
+	"float32": reflect.TypeOf(float32(0)),
+	"float64": reflect.TypeOf(float64(0)),
+}
+
+// scalar returns the type used to check values of the field. Types that are not
+// declared in the package, e.g. time.Duration, are checked as strings,
+// as they need custom parsers at runtime.
+func (p *pkg) scalar(expr ast.Expr) reflect.Type {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return reflect.PointerTo(p.scalar(t.X))
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return reflect.SliceOf(p.scalar(t.Elt))
+		}
+	case *ast.Ident:
+		if basic, ok := basicTypes[t.Name]; ok {
+			return basic
+		}
+
+		if underlying, ok := p.types[t.Name]; ok && !p.opaque[p.qualified(t.Name)] && !p.resolving[t.Name] {
+			p.resolving[t.Name] = true
+			defer delete(p.resolving, t.Name)
+
+			return p.scalar(underlying)
+		}
+	}
+
+	return basicTypes["string"]
+}
+
+// reflectType builds the struct type with the same fields and tags, to be populated by envset.
+func (m *structModel) reflectType() reflect.Type {
+	fields := make([]reflect.StructField, 0, len(m.fields))
+
+	for _, f := range m.fields {
+		var t reflect.Type
+
+		switch f.kind {
+		case scalarField:
+			t = f.scalar
+		default:
+			if t = f.nested.reflectType(); f.pointer {
+				t = reflect.PointerTo(t)
+			}
+
+			switch f.kind {
+			case sliceField:
+				t = reflect.SliceOf(t)
+			case mapField:
+				t = reflect.MapOf(basicTypes["string"], t)
+			}
+		}
+
+		fields = append(fields, reflect.StructField{Name: f.name, Type: t, Tag: f.tag})
+	}
+
+	return reflect.StructOf(fields)
+}
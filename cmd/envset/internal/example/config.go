@@ -0,0 +1,63 @@
+// Package example holds a configuration with a loader generated by envset gen.
+package example
+
+import (
+	"errors"
+	"time"
+)
+
+//go:generate go run github.com/dmytro-vovk/envset/cmd/envset gen -type Config -parser time.Duration=time.ParseDuration -var APP=WEB -test
+
+type Level string
+
+type Tags []string
+
+type Database struct {
+	Host     string `env:"DB_HOST" default:"localhost"`
+	Port     uint16 `env:"DB_PORT" default:"5432" min:"1"`
+	Password string `env:"DB_PASSWORD" secret:"true" minlen:"8" example:"password"`
+}
+
+func (db *Database) Validate() error {
+	if db.Host == "" {
+		return errors.New("empty host")
+	}
+
+	return nil
+}
+
+type Cache struct {
+	Size int `env:"CACHE_SIZE" default:"128"`
+}
+
+type Limits struct {
+	MaxConns int `env:"MAX_CONNS" default:"100" min:"1"`
+}
+
+func (l Limits) Validate() error {
+	if l.MaxConns > 1000 {
+		return errors.New("too many connections")
+	}
+
+	return nil
+}
+
+type Config struct {
+	Limits
+	Addr     string          `env:"{{APP}}_ADDR" default:":8080" pattern:"^[a-z.]*:[0-9]+$"`
+	Level    Level           `env:"LEVEL" default:"info" enum:"debug,info,warn" enum_fold:"true"`
+	Debug    bool            `env:"DEBUG,omitempty"`
+	Workers  int8            `env:"WORKERS" default:"4" min:"1" max:"64"`
+	Ratio    float32         `env:"RATIO" default:"0.5" min:"0" max:"1.5"`
+	Priority int             `env:"PRIORITY,omitempty" enum:"1,2,3"`
+	Timeout  time.Duration   `env:"TIMEOUT" default:"5s"`
+	Retries  []time.Duration `env:"RETRIES,omitempty" maxlen:"3" unique:"true" example:"1s,2s"`
+	Tags     Tags            `env:"TAGS,omitempty" pattern:"^[a-z]+$" unique:"true"`
+	Limit    *int            `env:"LIMIT,omitempty" max:"100"`
+	Ports    []*uint         `env:"PORTS,omitempty" unique:"true"`
+	DB       Database
+	DBPort   int `env:"DATABASE_PORT" default:"5432"`
+	Cache    *Cache
+	Token    string `env:"TOKEN" secret:"true"`
+	other    int
+}
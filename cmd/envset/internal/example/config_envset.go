@@ -0,0 +1,529 @@
+// Code generated by envset gen; DO NOT EDIT.
+
+package example
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dmytro-vovk/envset"
+)
+
+// LoadConfig populates Config from the source, the process environment if it is nil,
+// the same way as envset.Set does, without reflection.
+func LoadConfig(src envset.Source) (Config, error) {
+	var v Config
+
+	if src == nil {
+		src = envset.Environment()
+	}
+
+	source := fmt.Sprintf("%T", src)
+	if named, ok := src.(envset.NamedSource); ok {
+		source = named.SourceName()
+	}
+
+	var (
+		val  string
+		from string
+		ok   bool
+		err  error
+	)
+
+	// Limits.MaxConns
+	from = source
+	if val, ok = src.LookupEnv("MAX_CONNS"); !ok {
+		val, from = "100", envset.SourceDefault
+	}
+
+	if v.Limits.MaxConns, err = parseConfig_Limits_MaxConns(val); err != nil {
+		return Config{}, &envset.FieldError{Path: "Limits.MaxConns", Key: "MAX_CONNS", Value: val, Source: from, Err: err}
+	}
+
+	if _, ok := any(&v).(envset.Validator); !ok {
+		if validator, ok := any(&v.Limits).(envset.Validator); ok {
+			if err := validator.Validate(); err != nil {
+				return Config{}, fmt.Errorf("%s: %w", "Limits", err)
+			}
+		}
+	}
+
+	// Addr
+	from = source
+	if val, ok = src.LookupEnv("WEB_ADDR"); !ok {
+		val, from = ":8080", envset.SourceDefault
+	}
+
+	if v.Addr, err = parseConfig_Addr(val); err != nil {
+		return Config{}, &envset.FieldError{Path: "Addr", Key: "WEB_ADDR", Value: val, Source: from, Err: err}
+	}
+
+	// Level
+	from = source
+	if val, ok = src.LookupEnv("LEVEL"); !ok {
+		val, from = "info", envset.SourceDefault
+	}
+
+	if v.Level, err = parseConfig_Level(val); err != nil {
+		return Config{}, &envset.FieldError{Path: "Level", Key: "LEVEL", Value: val, Source: from, Err: err}
+	}
+
+	// Debug
+	from = source
+	val, _ = src.LookupEnv("DEBUG")
+
+	if val != "" {
+		if v.Debug, err = parseConfig_Debug(val); err != nil {
+			return Config{}, &envset.FieldError{Path: "Debug", Key: "DEBUG", Value: val, Source: from, Err: err}
+		}
+	}
+
+	// Workers
+	from = source
+	if val, ok = src.LookupEnv("WORKERS"); !ok {
+		val, from = "4", envset.SourceDefault
+	}
+
+	if v.Workers, err = parseConfig_Workers(val); err != nil {
+		return Config{}, &envset.FieldError{Path: "Workers", Key: "WORKERS", Value: val, Source: from, Err: err}
+	}
+
+	// Ratio
+	from = source
+	if val, ok = src.LookupEnv("RATIO"); !ok {
+		val, from = "0.5", envset.SourceDefault
+	}
+
+	if v.Ratio, err = parseConfig_Ratio(val); err != nil {
+		return Config{}, &envset.FieldError{Path: "Ratio", Key: "RATIO", Value: val, Source: from, Err: err}
+	}
+
+	// Priority
+	from = source
+	val, _ = src.LookupEnv("PRIORITY")
+
+	if val != "" {
+		if v.Priority, err = parseConfig_Priority(val); err != nil {
+			return Config{}, &envset.FieldError{Path: "Priority", Key: "PRIORITY", Value: val, Source: from, Err: err}
+		}
+	}
+
+	// Timeout
+	from = source
+	if val, ok = src.LookupEnv("TIMEOUT"); !ok {
+		val, from = "5s", envset.SourceDefault
+	}
+
+	if val == "" {
+		return Config{}, &envset.FieldError{Path: "Timeout", Key: "TIMEOUT", Value: val, Source: from, Err: envset.NewMissingValueError("TIMEOUT")}
+	}
+
+	if v.Timeout, err = time.ParseDuration(val); err != nil {
+		return Config{}, &envset.FieldError{Path: "Timeout", Key: "TIMEOUT", Value: val, Source: from, Err: err}
+	}
+
+	// Retries
+	from = source
+	val, _ = src.LookupEnv("RETRIES")
+
+	if val != "" {
+		if v.Retries, err = parseConfig_Retries(val); err != nil {
+			return Config{}, &envset.FieldError{Path: "Retries", Key: "RETRIES", Value: val, Source: from, Err: err}
+		}
+	}
+
+	// Tags
+	from = source
+	val, _ = src.LookupEnv("TAGS")
+
+	if val != "" {
+		if v.Tags, err = parseConfig_Tags(val); err != nil {
+			return Config{}, &envset.FieldError{Path: "Tags", Key: "TAGS", Value: val, Source: from, Err: err}
+		}
+	}
+
+	// Limit
+	from = source
+	val, _ = src.LookupEnv("LIMIT")
+
+	if val != "" {
+		if v.Limit, err = parseConfig_Limit(val); err != nil {
+			return Config{}, &envset.FieldError{Path: "Limit", Key: "LIMIT", Value: val, Source: from, Err: err}
+		}
+	}
+
+	// Ports
+	from = source
+	val, _ = src.LookupEnv("PORTS")
+
+	if val != "" {
+		if v.Ports, err = parseConfig_Ports(val); err != nil {
+			return Config{}, &envset.FieldError{Path: "Ports", Key: "PORTS", Value: val, Source: from, Err: err}
+		}
+	}
+
+	// DB.Host
+	from = source
+	if val, ok = src.LookupEnv("DB_HOST"); !ok {
+		val, from = "localhost", envset.SourceDefault
+	}
+
+	if v.DB.Host, err = parseConfig_DB_Host(val); err != nil {
+		return Config{}, &envset.FieldError{Path: "DB.Host", Key: "DB_HOST", Value: val, Source: from, Err: err}
+	}
+
+	// DB.Port
+	from = source
+	if val, ok = src.LookupEnv("DB_PORT"); !ok {
+		val, from = "5432", envset.SourceDefault
+	}
+
+	if v.DB.Port, err = parseConfig_DB_Port(val); err != nil {
+		return Config{}, &envset.FieldError{Path: "DB.Port", Key: "DB_PORT", Value: val, Source: from, Err: err}
+	}
+
+	// DB.Password
+	from = source
+	if val, ok = src.LookupEnv("DB_PASSWORD"); !ok {
+		return Config{}, &envset.FieldError{Path: "DB.Password", Key: "DB_PASSWORD", Err: envset.NewMissingValueError("DB_PASSWORD")}
+	}
+
+	if v.DB.Password, err = parseConfig_DB_Password(val); err != nil {
+		return Config{}, &envset.FieldError{Path: "DB.Password", Key: "DB_PASSWORD", Redacted: true, Source: from, Err: envset.Redact(err)}
+	}
+
+	if validator, ok := any(&v.DB).(envset.Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return Config{}, fmt.Errorf("%s: %w", "DB", err)
+		}
+	}
+
+	// DBPort
+	from = source
+	if val, ok = src.LookupEnv("DATABASE_PORT"); !ok {
+		val, from = "5432", envset.SourceDefault
+	}
+
+	if v.DBPort, err = parseConfig_DBPort(val); err != nil {
+		return Config{}, &envset.FieldError{Path: "DBPort", Key: "DATABASE_PORT", Value: val, Source: from, Err: err}
+	}
+
+	v.Cache = new(Cache)
+
+	// Cache.Size
+	from = source
+	if val, ok = src.LookupEnv("CACHE_SIZE"); !ok {
+		val, from = "128", envset.SourceDefault
+	}
+
+	if v.Cache.Size, err = parseConfig_Cache_Size(val); err != nil {
+		return Config{}, &envset.FieldError{Path: "Cache.Size", Key: "CACHE_SIZE", Value: val, Source: from, Err: err}
+	}
+
+	if validator, ok := any(v.Cache).(envset.Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return Config{}, fmt.Errorf("%s: %w", "Cache", err)
+		}
+	}
+
+	// Token
+	from = source
+	if val, ok = src.LookupEnv("TOKEN"); !ok {
+		return Config{}, &envset.FieldError{Path: "Token", Key: "TOKEN", Err: envset.NewMissingValueError("TOKEN")}
+	}
+
+	if v.Token, err = parseConfig_Token(val); err != nil {
+		return Config{}, &envset.FieldError{Path: "Token", Key: "TOKEN", Redacted: true, Source: from, Err: envset.Redact(err)}
+	}
+
+	if validator, ok := any(&v).(envset.Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return Config{}, err
+		}
+	}
+
+	return v, nil
+}
+
+func parseConfig_Limits_MaxConns(val string) (v int, err error) {
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return v, err
+	}
+
+	i := n
+
+	if i < int(1) {
+		return v, fmt.Errorf("value %v is less than the minimal value %v", val, int(1))
+	}
+
+	return i, nil
+}
+
+var parseConfig_Addr_pattern = regexp.MustCompile("^[a-z.]*:[0-9]+$")
+
+func parseConfig_Addr(val string) (v string, err error) {
+	if !parseConfig_Addr_pattern.MatchString(val) {
+		return v, envset.ErrInvalidValue
+	}
+
+	return val, nil
+}
+
+func parseConfig_Level(val string) (v Level, err error) {
+	switch {
+	case strings.EqualFold("debug", val):
+		val = "debug"
+	case strings.EqualFold("info", val):
+		val = "info"
+	case strings.EqualFold("warn", val):
+		val = "warn"
+	default:
+		return v, fmt.Errorf("%w: %s is not one of %s", envset.ErrInvalidValue, val, "debug, info, warn")
+	}
+
+	return Level(val), nil
+}
+
+func parseConfig_Debug(val string) (v bool, err error) {
+	var b bool
+
+	switch strings.ToLower(val) {
+	case "1", "t", "true", "y", "yay", "yes":
+		b = true
+	case "0", "f", "false", "n", "nay", "no":
+	default:
+		return v, errors.New("invalid bool value " + val)
+	}
+
+	return b, nil
+}
+
+func parseConfig_Workers(val string) (v int8, err error) {
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return v, err
+	}
+
+	i := int8(n)
+
+	if i < int8(1) {
+		return v, fmt.Errorf("value %v is less than the minimal value %v", val, int8(1))
+	}
+
+	if i > int8(64) {
+		return v, fmt.Errorf("value %v is greater than the maximal value %v", val, int8(64))
+	}
+
+	return i, nil
+}
+
+func parseConfig_Ratio(val string) (v float32, err error) {
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return v, err
+	}
+
+	i := float32(f)
+
+	if i < float32(float64(0)) {
+		return v, fmt.Errorf("value %v is less than the minimal value %v", val, float32(float64(0)))
+	}
+
+	if i > float32(float64(1.5)) {
+		return v, fmt.Errorf("value %v is greater than the maximal value %v", val, float32(float64(1.5)))
+	}
+
+	return i, nil
+}
+
+func parseConfig_Priority(val string) (v int, err error) {
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return v, err
+	}
+
+	if f, _ := strconv.ParseFloat(val, 64); !(f == 1 || f == 2 || f == 3) {
+		return v, fmt.Errorf("%w: %s is not one of %s", envset.ErrInvalidValue, val, "1, 2, 3")
+	}
+
+	i := n
+
+	return i, nil
+}
+
+func parseConfig_Retries(val string) (v []time.Duration, err error) {
+	parts := strings.Split(val, ",")
+
+	switch n := len(parts); {
+	case n > 3:
+		return v, fmt.Errorf("%w: length %d is greater than the maximal length %d", envset.ErrInvalidValue, n, 3)
+	}
+
+	elems := make([]time.Duration, len(parts))
+
+	for i, part := range parts {
+		if elems[i], err = time.ParseDuration(part); err != nil {
+			return v, fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+
+	seen := make(map[time.Duration]int, len(elems))
+
+	for i, elem := range elems {
+		if j, ok := seen[elem]; ok {
+			return v, fmt.Errorf("%w: element %d duplicates element %d", envset.ErrInvalidValue, i, j)
+		}
+
+		seen[elem] = i
+	}
+
+	return elems, nil
+}
+
+func parseConfig_Tags_elem(val string) (v string, err error) {
+	return val, nil
+}
+
+var parseConfig_Tags_pattern = regexp.MustCompile("^[a-z]+$")
+
+func parseConfig_Tags(val string) (v Tags, err error) {
+	parts := strings.Split(val, ",")
+
+	elems := make([]string, len(parts))
+
+	for i, part := range parts {
+		if !parseConfig_Tags_pattern.MatchString(part) {
+			return v, fmt.Errorf("element %d: value %s does not match pattern %s", i, part, parseConfig_Tags_pattern)
+		}
+
+		if elems[i], err = parseConfig_Tags_elem(part); err != nil {
+			return v, fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+
+	seen := make(map[string]int, len(elems))
+
+	for i, elem := range elems {
+		if j, ok := seen[elem]; ok {
+			return v, fmt.Errorf("%w: element %d duplicates element %d", envset.ErrInvalidValue, i, j)
+		}
+
+		seen[elem] = i
+	}
+
+	return Tags(elems), nil
+}
+
+func parseConfig_Limit(val string) (v *int, err error) {
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return v, err
+	}
+
+	i := n
+
+	if i > int(100) {
+		return v, fmt.Errorf("value %v is greater than the maximal value %v", val, int(100))
+	}
+
+	x := i
+
+	return &x, nil
+}
+
+func parseConfig_Ports_elem(val string) (v *uint, err error) {
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return v, err
+	}
+
+	i := uint(n)
+
+	x := i
+
+	return &x, nil
+}
+
+func parseConfig_Ports(val string) (v []*uint, err error) {
+	parts := strings.Split(val, ",")
+
+	elems := make([]*uint, len(parts))
+
+	for i, part := range parts {
+		if elems[i], err = parseConfig_Ports_elem(part); err != nil {
+			return v, fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+
+	seen := make(map[uint]int, len(elems))
+
+	for i, elem := range elems {
+		if j, ok := seen[*elem]; ok {
+			return v, fmt.Errorf("%w: element %d duplicates element %d", envset.ErrInvalidValue, i, j)
+		}
+
+		seen[*elem] = i
+	}
+
+	return elems, nil
+}
+
+func parseConfig_DB_Host(val string) (v string, err error) {
+	return val, nil
+}
+
+func parseConfig_DB_Port(val string) (v uint16, err error) {
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return v, err
+	}
+
+	i := uint16(n)
+
+	if i < uint16(1) {
+		return v, fmt.Errorf("value %v is less than the minimal value %v", val, uint16(1))
+	}
+
+	return i, nil
+}
+
+func parseConfig_DB_Password(val string) (v string, err error) {
+	switch n := utf8.RuneCountInString(val); {
+	case n < 8:
+		return v, fmt.Errorf("%w: length %d is less than the minimal length %d", envset.ErrInvalidValue, n, 8)
+	}
+
+	return val, nil
+}
+
+func parseConfig_DBPort(val string) (v int, err error) {
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return v, err
+	}
+
+	i := n
+
+	return i, nil
+}
+
+func parseConfig_Cache_Size(val string) (v int, err error) {
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return v, err
+	}
+
+	i := n
+
+	return i, nil
+}
+
+func parseConfig_Token(val string) (v string, err error) {
+	return val, nil
+}
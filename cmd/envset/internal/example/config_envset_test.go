@@ -0,0 +1,784 @@
+// Code generated by envset gen; DO NOT EDIT.
+
+package example
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dmytro-vovk/envset"
+)
+
+func TestLoadConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		src  envset.MapSource
+	}{
+		{name: "empty", src: envset.MapSource{}},
+		{name: "values", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "MAX_CONNS=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "invalid",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "MAX_CONNS=", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "WEB_ADDR=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      "invalid",
+			"WORKERS":       "4",
+		}},
+		{name: "WEB_ADDR=", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      "",
+			"WORKERS":       "4",
+		}},
+		{name: "LEVEL=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "invalid",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "LEVEL=", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "DEBUG=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "invalid",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "DEBUG=", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "WORKERS=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "invalid",
+		}},
+		{name: "WORKERS=", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "",
+		}},
+		{name: "RATIO=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "invalid",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "RATIO=", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "PRIORITY=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "invalid",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "PRIORITY=", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "TIMEOUT=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "invalid",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "TIMEOUT=", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "RETRIES=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "invalid",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "RETRIES=", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "TAGS=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "invalid",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "TAGS=", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "LIMIT=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "invalid",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "LIMIT=", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "PORTS=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "invalid",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "PORTS=", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "DB_HOST=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "invalid",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "DB_HOST=", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "DB_PORT=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "invalid",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "DB_PORT=", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "DB_PASSWORD=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "invalid",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "DB_PASSWORD=", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "DATABASE_PORT=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "invalid",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "DATABASE_PORT=", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "CACHE_SIZE=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "invalid",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "CACHE_SIZE=", src: envset.MapSource{
+			"CACHE_SIZE":    "",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "value",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "TOKEN=invalid", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "invalid",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+		{name: "TOKEN=", src: envset.MapSource{
+			"CACHE_SIZE":    "128",
+			"DATABASE_PORT": "5432",
+			"DB_HOST":       "localhost",
+			"DB_PASSWORD":   "password",
+			"DB_PORT":       "5432",
+			"DEBUG":         "true",
+			"LEVEL":         "info",
+			"LIMIT":         "1",
+			"MAX_CONNS":     "100",
+			"PORTS":         "1",
+			"PRIORITY":      "1",
+			"RATIO":         "0.5",
+			"RETRIES":       "1s,2s",
+			"TAGS":          "value",
+			"TIMEOUT":       "5s",
+			"TOKEN":         "",
+			"WEB_ADDR":      ":8080",
+			"WORKERS":       "4",
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := LoadConfig(tc.src)
+
+			var want Config
+
+			wantErr := envset.Set(&want,
+				envset.WithSource(tc.src),
+				envset.WithEnvTag("env"),
+				envset.WithDefaultTag("default"),
+				envset.WithSliceSeparator(","),
+				envset.WithKeyVar("APP", "WEB"),
+				envset.WithTypeParser(time.ParseDuration),
+			)
+
+			if fmt.Sprint(err) != fmt.Sprint(wantErr) {
+				t.Fatalf("LoadConfig() error = %v, envset.Set() error = %v", err, wantErr)
+			}
+
+			if wantErr == nil && !reflect.DeepEqual(got, want) {
+				t.Errorf("LoadConfig() = %+v, envset.Set() = %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"io"
+
+	"github.com/dmytro-vovk/envset"
+)
+
+// list writes the variables of the struct built from the model with envset.UsageType.
+// Types are listed as the model resolves them, e.g. named string types and custom types as string.
+func list(w io.Writer, model *structModel, cfg config) error {
+	options, err := cfg.options()
+	if err != nil {
+		return err
+	}
+
+	return envset.UsageType(w, model.reflectType(), options...)
+}
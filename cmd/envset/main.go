@@ -0,0 +1,256 @@
+// Command envset inspects structs tagged for github.com/dmytro-vovk/envset
+// without compiling the code declaring them.
+//
+// Usage:
+//
+//	envset list -type Config [flags] [package]
+//	envset check -type Config [-env-file .env] [flags] [package]
+//	envset gen -type Config [-parser Type=Func] [-test] [flags] [package]
+//
+// The list command prints the environment variables of the struct with their types,
+// defaults, constraints and descriptions. The check command validates the current
+// environment, and optionally a .env file, against the struct and exits with status 1
+// if there are missing or invalid values. The gen command writes the LoadConfig function
+// populating the struct without reflection, the same way as envset.Set does, meant for go:generate:
+//
+//	//go:generate go run github.com/dmytro-vovk/envset/cmd/envset gen -type Config -test
+//
+// The generated test compares LoadConfig with envset.Set for sample values.
+//
+// The package is a directory or an import path, the current directory by default.
+// Struct types declared in imported packages are read from their source as well, unless the field
+// is tagged, e.g. time.Time, as it then needs a custom parser at runtime. Such fields, and other types
+// declared outside the package, such as time.Duration, are only checked for presence;
+// use -custom to treat other types the same way, e.g. -custom Level or -custom url.URL.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/dmytro-vovk/envset"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Environ(), os.Stdout, os.Stderr))
+}
+
+const usage = `Usage:
+  envset list -type Config [flags] [package]
+  envset check -type Config [-env-file .env] [flags] [package]
+  envset gen -type Config [-parser Type=Func] [-test] [flags] [package]
+`
+
+// Exit codes
+const (
+	exitOK       = 0
+	exitProblems = 1
+	exitUsage    = 2
+)
+
+// stringList is a repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(val string) error {
+	*l = append(*l, val)
+	return nil
+}
+
+// config holds the flags common to all commands.
+type config struct {
+	typeName     string
+	envTag       string
+	defaultTag   string
+	separator    string
+	indexPattern string
+	vars         stringList
+	custom       stringList
+	// check command flags
+	envFile   string
+	ignoreEnv bool
+	expand    bool
+	// gen command flags
+	parsers stringList
+	output  string
+	test    bool
+}
+
+func run(args, environ []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		_, _ = io.WriteString(stderr, usage)
+		return exitUsage
+	}
+
+	command := args[0]
+	if command != "list" && command != "check" && command != "gen" {
+		_, _ = fmt.Fprintf(stderr, "unknown command %q\n%s", command, usage)
+		return exitUsage
+	}
+
+	var cfg config
+
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&cfg.typeName, "type", "", "name of the struct `type` (required)")
+	flags.StringVar(&cfg.envTag, "tag", "env", "tag holding variable names")
+	flags.StringVar(&cfg.defaultTag, "default-tag", "default", "tag holding default values")
+	flags.StringVar(&cfg.separator, "separator", ",", "separator of slice values")
+	flags.StringVar(&cfg.indexPattern, "index-pattern", "{key}_{index}_", "key prefix pattern of slice and map elements")
+	flags.Var(&cfg.vars, "var", "key template variable as `NAME=VALUE`, repeatable")
+	flags.Var(&cfg.custom, "custom", "`type` parsed by a custom parser at runtime, e.g. Level or url.URL, repeatable")
+
+	if command == "check" {
+		flags.StringVar(&cfg.envFile, "env-file", "", "`path` to .env file, the environment takes precedence over it")
+		flags.BoolVar(&cfg.ignoreEnv, "ignore-env", false, "do not check the process environment")
+		flags.BoolVar(&cfg.expand, "expand", false, "expand ${VAR} references in all values")
+	}
+
+	if command == "gen" {
+		flags.Var(&cfg.parsers, "parser", "custom parser of a type as `Type=Func`, e.g. time.Duration=time.ParseDuration, repeatable")
+		flags.StringVar(&cfg.output, "output", "", "output `file`, the lowercase type name with _envset.go suffix in the package directory by default")
+		flags.BoolVar(&cfg.test, "test", false, "also write the test comparing the generated loader with envset.Set")
+	}
+
+	if err := flags.Parse(args[1:]); err != nil {
+		return exitUsage
+	}
+
+	if cfg.typeName == "" || flags.NArg() > 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	path := "."
+	if flags.NArg() == 1 {
+		path = flags.Arg(0)
+	}
+
+	p, model, err := loadModel(path, cfg)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	switch command {
+	case "gen":
+		if err := generate(p, model, cfg); err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+
+		return exitOK
+	case "list":
+		if err := list(stdout, model, cfg); err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+
+		return exitOK
+	}
+
+	return check(stdout, stderr, model, cfg, environ)
+}
+
+func loadModel(path string, cfg config) (*pkg, *structModel, error) {
+	p, err := loadPackage(path, cfg.envTag)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, name := range cfg.custom {
+		p.opaque[name] = true
+	}
+
+	// Package types having custom parsers are not structs to be populated
+	for _, parser := range cfg.parsers {
+		if typeName, _, ok := strings.Cut(parser, "="); ok {
+			p.opaque[strings.TrimPrefix(typeName, "*")] = true
+		}
+	}
+
+	model, err := p.structModel(cfg.typeName)
+
+	return p, model, err
+}
+
+// options translates the flags to envset options.
+func (cfg config) options() ([]envset.Option, error) {
+	options := []envset.Option{
+		envset.WithEnvTag(cfg.envTag),
+		envset.WithDefaultTag(cfg.defaultTag),
+		envset.WithSliceSeparator(cfg.separator),
+		envset.WithIndexPattern(cfg.indexPattern),
+	}
+
+	for _, v := range cfg.vars {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid -var %q, expected NAME=VALUE", v)
+		}
+
+		options = append(options, envset.WithKeyVar(name, value))
+	}
+
+	if cfg.expand {
+		options = append(options, envset.WithExpansion())
+	}
+
+	return options, nil
+}
+
+// check populates the struct built from the model and reports every problem.
+func check(stdout, stderr io.Writer, model *structModel, cfg config, environ []string) int {
+	options, err := cfg.options()
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	values := make(envset.MapSource)
+
+	if cfg.envFile != "" {
+		fromFile, err := readDotEnv(cfg.envFile)
+		if err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+
+		for key, val := range fromFile {
+			values[key] = val
+		}
+	}
+
+	if !cfg.ignoreEnv {
+		for _, kv := range environ {
+			if key, val, ok := strings.Cut(kv, "="); ok {
+				values[key] = val
+			}
+		}
+	}
+
+	options = append(options, envset.WithSource(values), envset.WithAllErrors())
+
+	err = envset.SetValue(reflect.New(model.reflectType()), options...)
+	if err == nil {
+		_, _ = fmt.Fprintln(stdout, "OK")
+		return exitOK
+	}
+
+	var errs envset.Errors
+	if !errors.As(err, &errs) {
+		errs = envset.Errors{err}
+	}
+
+	for _, err := range errs {
+		_, _ = fmt.Fprintln(stdout, err)
+	}
+
+	return exitProblems
+}
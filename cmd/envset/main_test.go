@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestList(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	code := run([]string{"list", "-type", "Config", "-var", "APP=WEB", "testdata/config"}, nil, &stdout, &stderr)
+	require.Equal(t, exitOK, code, stderr.String())
+	assert.Equal(t, `VARIABLE          TYPE      DEFAULT  CONSTRAINTS           DESCRIPTION
+WEB_ADDR          string    :8080                          Listen address
+LEVEL             string    info     enum=debug,info,warn
+TIMEOUT           string
+PASSWORD          string    ******
+TAGS              []string           unique=true
+BACKEND_<n>_HOST  string             required              Backend host
+BACKEND_<n>_PORT  int       80       min=1 max=65535
+`, stdout.String())
+}
+
+func TestListImported(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	code := run([]string{"list", "-type", "Config", "testdata/external"}, nil, &stdout, &stderr)
+	require.Equal(t, exitOK, code, stderr.String())
+	assert.Equal(t, `VARIABLE  TYPE    DEFAULT  CONSTRAINTS  DESCRIPTION
+DB_HOST   string           required
+PORT      int              required
+START     string
+ENDPOINT  string
+`, stdout.String())
+
+	stdout.Reset()
+
+	code = run([]string{"check", "-type", "Config", "testdata/external"}, []string{"PORT=80"}, &stdout, &stderr)
+	require.Equal(t, exitProblems, code, stderr.String())
+	assert.Equal(t, "DB.Host (DB_HOST): value required, but not set: DB_HOST\n", stdout.String())
+}
+
+func TestListUnresolved(t *testing.T) {
+	t.Setenv("GOPROXY", "off")
+
+	var stdout, stderr bytes.Buffer
+
+	code := run([]string{"list", "-type", "Config", "testdata/unresolved"}, nil, &stdout, &stderr)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr.String(), "field DB: type sub.DB declared outside the package, use -custom: ")
+
+	stdout.Reset()
+	stderr.Reset()
+
+	code = run([]string{"list", "-type", "Config", "-custom", "sub.DB", "testdata/unresolved"}, nil, &stdout, &stderr)
+	require.Equal(t, exitOK, code, stderr.String())
+	assert.Equal(t, `VARIABLE  TYPE  DEFAULT  CONSTRAINTS  DESCRIPTION
+PORT      int            required
+`, stdout.String())
+}
+
+func TestCheck(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(envFile, []byte(`# backends
+BACKEND_0_HOST="db.local"
+export BACKEND_0_PORT=5432 # postgres
+LEVEL='warn'
+`), 0o600))
+
+	t.Run("ok", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := run([]string{"check", "-type", "Config", "-var", "APP=WEB", "-env-file", envFile, "testdata/config"},
+			[]string{"TIMEOUT=5s"}, &stdout, &stderr)
+		require.Equal(t, exitOK, code, stderr.String())
+		assert.Equal(t, "OK\n", stdout.String())
+	})
+
+	t.Run("environment takes precedence", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := run([]string{"check", "-type", "Config", "-var", "APP=WEB", "-env-file", envFile, "testdata/config"},
+			[]string{"BACKEND_0_PORT=0", "LEVEL=trace"}, &stdout, &stderr)
+		require.Equal(t, exitProblems, code, stderr.String())
+		assert.Equal(t, `Level (LEVEL): invalid value: trace is not one of debug, info, warn
+Backends[0].Port (BACKEND_0_PORT): value 0 is less than the minimal value 1
+`, stdout.String())
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := run([]string{"check", "-type", "Config", "-var", "APP=WEB", "-ignore-env", "testdata/config"},
+			[]string{"BACKEND_0_HOST=db.local"}, &stdout, &stderr)
+		require.Equal(t, exitProblems, code, stderr.String())
+		assert.Equal(t, "Backends (BACKEND): value required, but not set: BACKEND\n", stdout.String())
+	})
+
+	t.Run("conditions with field errors", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := run([]string{"check", "-type", "Config", "testdata/conditions"},
+			[]string{"PORT=0", "TLS_ENABLED=yes"}, &stdout, &stderr)
+		require.Equal(t, exitProblems, code, stderr.String())
+		assert.Equal(t, `Port (PORT): value 0 is less than the minimal value 1
+TLSKeyFile (TLS_KEY_FILE): value required if TLSEnabled=true, but not set: TLS_KEY_FILE
+`, stdout.String())
+	})
+}
+
+func TestUsageErrors(t *testing.T) {
+	for name, args := range map[string][]string{
+		"no command":       nil,
+		"unknown command":  {"show"},
+		"no type":          {"list", "testdata/config"},
+		"unknown type":     {"list", "-type", "Missing", "testdata/config"},
+		"not a struct":     {"list", "-type", "Level", "testdata/config"},
+		"undefined var":    {"list", "-type", "Config", "testdata/config"},
+		"missing env file": {"check", "-type", "Config", "-var", "APP=WEB", "-env-file", "testdata/missing.env", "testdata/config"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			assert.Equal(t, exitUsage, run(args, nil, &stdout, &stderr))
+			assert.NotEmpty(t, stderr.String())
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "config_envset.go")
+
+	var stdout, stderr bytes.Buffer
+
+	code := run([]string{"gen", "-type", "Config", "-parser", "time.Duration=time.ParseDuration", "-var", "APP=WEB",
+		"-test", "-output", output, "internal/example"}, nil, &stdout, &stderr)
+	require.Equal(t, exitOK, code, stderr.String())
+
+	// The example loader is tested against envset.Set by the generated test
+	for generated, committed := range map[string]string{
+		output: "internal/example/config_envset.go",
+		strings.TrimSuffix(output, ".go") + "_test.go": "internal/example/config_envset_test.go",
+	} {
+		want, err := os.ReadFile(committed)
+		require.NoError(t, err)
+
+		got, err := os.ReadFile(generated)
+		require.NoError(t, err)
+
+		assert.Equal(t, string(want), string(got), "%s is outdated, run go generate", committed)
+	}
+}
+
+func TestGenerateNotSupported(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	code := run([]string{"gen", "-type", "Config", "-var", "APP=WEB", "-output", filepath.Join(t.TempDir(), "out.go"), "testdata/config"},
+		nil, &stdout, &stderr)
+	assert.Equal(t, exitUsage, code)
+	assert.Equal(t, "field Timeout: type time.Duration needs a parser, use -parser time.Duration=Func\n", stderr.String())
+
+	stderr.Reset()
+
+	code = run([]string{"gen", "-type", "Config", "-var", "APP=WEB", "-parser", "time.Duration=time.ParseDuration",
+		"-output", filepath.Join(t.TempDir(), "out.go"), "testdata/config"}, nil, &stdout, &stderr)
+	assert.Equal(t, exitUsage, code)
+	assert.Equal(t, "field Backends: slices and maps of structs are not supported by the generator\n", stderr.String())
+
+	stderr.Reset()
+
+	code = run([]string{"gen", "-type", "Config", "-output", filepath.Join(t.TempDir(), "out.go"), "testdata/external"},
+		nil, &stdout, &stderr)
+	assert.Equal(t, exitUsage, code)
+	assert.Equal(t, "field DB: struct types declared outside the package are not supported by the generator\n", stderr.String())
+}
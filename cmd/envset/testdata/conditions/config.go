@@ -0,0 +1,7 @@
+package conditions
+
+type Config struct {
+	Port       int    `env:"PORT" min:"1"`
+	TLSEnabled bool   `env:"TLS_ENABLED" default:"false"`
+	TLSKeyFile string `env:"TLS_KEY_FILE,omitempty" required_if:"TLSEnabled=true"`
+}
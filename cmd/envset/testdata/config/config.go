@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+type Level string
+
+type Backend struct {
+	Host string `env:"HOST" desc:"Backend host"`
+	Port int    `env:"PORT" default:"80" min:"1" max:"65535"`
+}
+
+type Config struct {
+	Addr     string        `env:"{{APP}}_ADDR" default:":8080" desc:"Listen address"`
+	Level    Level         `env:"LEVEL" default:"info" enum:"debug,info,warn"`
+	Timeout  time.Duration `env:"TIMEOUT,omitempty"`
+	Password string        `env:"PASSWORD" secret:"true" default:"changeme"`
+	Tags     []string      `env:"TAGS,omitempty" unique:"true"`
+	Backends []Backend     `env:"BACKEND"`
+	private  int
+}
@@ -0,0 +1,15 @@
+package external
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/dmytro-vovk/envset/cmd/envset/testdata/external/sub"
+)
+
+type Config struct {
+	DB       sub.DB
+	Port     int       `env:"PORT"`
+	Start    time.Time `env:"START,omitempty"`
+	Endpoint url.URL   `env:"ENDPOINT,omitempty"`
+}
@@ -0,0 +1,5 @@
+package sub
+
+type DB struct {
+	Host string `env:"DB_HOST"`
+}
@@ -0,0 +1,8 @@
+package unresolved
+
+import "example.com/missing/sub"
+
+type Config struct {
+	DB   sub.DB
+	Port int `env:"PORT"`
+}
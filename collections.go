@@ -0,0 +1,314 @@
+package envset
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// isStructSlice reports whether t is []Struct or []*Struct, populated from indexed keys.
+// Slices of types having a custom parser are parsed from a single value instead.
+func (p *parser) isStructSlice(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+
+	return p.isStructElem(t.Elem())
+}
+
+// isStructElem reports whether collection elements of type t are structs populated field by field.
+func (p *parser) isStructElem(t reflect.Type) bool {
+	_, custom := p.customTypes[t]
+
+	return !custom && structType(t) != nil
+}
+
+// structType returns the struct type of t, dereferencing a pointer, or nil.
+func structType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return t
+}
+
+// isStructMap reports whether t is map[string]Struct or map[string]*Struct.
+func (p *parser) isStructMap(t reflect.Type) bool {
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return false
+	}
+
+	return p.isStructElem(t.Elem())
+}
+
+// indexPrefix builds the key prefix of a collection element.
+func (p *parser) indexPrefix(key, index string) string {
+	return strings.NewReplacer("{key}", key, "{index}", index).Replace(p.indexPattern)
+}
+
+// setStructSlice populates a slice of structs from indexed keys,
+// e.g. BACKEND_0_HOST, BACKEND_1_HOST. Elements are read starting from 0
+// until there is an index with none of the element keys set.
+func (p *parser) setStructSlice(f reflect.Value, field reflect.StructField, s scope) error {
+	if !f.IsZero() {
+		p.recordPreset(s, field, f)
+		return nil
+	}
+
+	key, ok, optional, err := p.tagKey(field.Tag)
+	if err != nil {
+		return s.fieldError(field.Name, "", err)
+	}
+
+	if !ok {
+		return nil
+	}
+
+	key = s.prefix + key
+
+	// Without the index every element would read the same keys
+	if !strings.Contains(p.indexPattern, "{index}") {
+		return s.fieldError(field.Name, key, ErrIndexPattern)
+	}
+
+	st := structType(f.Type().Elem())
+	slice := reflect.MakeSlice(f.Type(), 0, 0)
+
+	var errs Errors
+
+	for i := 0; ; i++ {
+		index := strconv.Itoa(i)
+		es := s.element(field.Name, index, p.indexPrefix(key, index))
+
+		if !p.hasKeys(st, es) {
+			break
+		}
+
+		elem := reflect.New(st)
+		if p.collect(&errs, p.setStruct(elem.Elem(), es)) {
+			return errs[0]
+		}
+
+		if f.Type().Elem().Kind() == reflect.Pointer {
+			slice = reflect.Append(slice, elem)
+		} else {
+			slice = reflect.Append(slice, elem.Elem())
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if slice.Len() == 0 {
+		if optional {
+			p.record(s, field, key, SourceUnset, "")
+			return nil
+		}
+
+		return s.fieldError(field.Name, key, NewMissingValueError(key))
+	}
+
+	f.Set(slice)
+
+	return nil
+}
+
+// hasKeys reports whether any key of struct type t is set in the scope.
+func (p *parser) hasKeys(t reflect.Type, s scope) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		_, custom := p.customTypes[field.Type]
+
+		// Nested structs share the prefix
+		if st := structType(field.Type); st != nil && !custom {
+			if p.hasKeys(st, s.child(field.Name)) {
+				return true
+			}
+			continue
+		}
+
+		key, ok, _, err := p.tagKey(field.Tag)
+		if !ok || err != nil {
+			continue
+		}
+
+		if p.isStructSlice(field.Type) && !custom {
+			es := s.element(field.Name, "0", p.indexPrefix(s.prefix+key, "0"))
+			if p.hasKeys(structType(field.Type.Elem()), es) {
+				return true
+			}
+			continue
+		}
+
+		if p.isStructMap(field.Type) && !custom {
+			continue
+		}
+
+		if _, ok := p.source.LookupEnv(p.mapKey(s.names(field.Name), s.prefix+key)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setStructMap populates a map of structs, discovering map keys from the source keys,
+// e.g. TENANT_ACME_URL and TENANT_GLOBEX_URL result in ACME and GLOBEX entries.
+func (p *parser) setStructMap(f reflect.Value, field reflect.StructField, s scope) error {
+	if !f.IsZero() {
+		p.recordPreset(s, field, f)
+		return nil
+	}
+
+	key, ok, optional, err := p.tagKey(field.Tag)
+	if err != nil {
+		return s.fieldError(field.Name, "", err)
+	}
+
+	if !ok {
+		return nil
+	}
+
+	key = s.prefix + key
+
+	if !strings.Contains(p.indexPattern, "{index}") {
+		return s.fieldError(field.Name, key, ErrIndexPattern)
+	}
+
+	st := structType(f.Type().Elem())
+
+	names, err := p.mapNames(st, field, key, s)
+	if err != nil {
+		return s.fieldError(field.Name, key, err)
+	}
+
+	if len(names) == 0 {
+		if optional {
+			p.record(s, field, key, SourceUnset, "")
+			return nil
+		}
+
+		return s.fieldError(field.Name, key, NewMissingValueError(key))
+	}
+
+	m := reflect.MakeMapWithSize(f.Type(), len(names))
+
+	var errs Errors
+
+	for _, name := range names {
+		elem := reflect.New(st)
+		if p.collect(&errs, p.setStruct(elem.Elem(), s.element(field.Name, name, p.indexPrefix(key, name)))) {
+			return errs[0]
+		}
+
+		if f.Type().Elem().Kind() == reflect.Pointer {
+			m.SetMapIndex(reflect.ValueOf(name).Convert(f.Type().Key()), elem)
+		} else {
+			m.SetMapIndex(reflect.ValueOf(name).Convert(f.Type().Key()), elem.Elem())
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	f.Set(m)
+
+	return nil
+}
+
+// mapNames lists the distinct name segments of source keys
+// that match keys of struct type t under the collection field.
+func (p *parser) mapNames(t reflect.Type, field reflect.StructField, key string, s scope) ([]string, error) {
+	lister, ok := p.source.(KeyLister)
+	if !ok {
+		return nil, ErrKeysNotListable
+	}
+
+	// Build the keys of an element with a placeholder name, then find the name
+	// in place of the placeholder. This way key mapping is accounted for,
+	// as long as the mapper keeps the placeholder intact.
+	const placeholder = "\x00"
+
+	type affixes struct{ prefix, suffix string }
+
+	var patterns []affixes
+
+	for _, k := range p.structKeys(t, s.element(field.Name, placeholder, p.indexPrefix(key, placeholder))) {
+		if prefix, suffix, ok := strings.Cut(k, placeholder); ok && !strings.Contains(suffix, placeholder) {
+			patterns = append(patterns, affixes{prefix: prefix, suffix: suffix})
+		}
+	}
+
+	// A key may match several patterns when one element key ends with another,
+	// e.g. TENANT_ACME_TOKEN_URL matches both _URL and _TOKEN_URL suffixes,
+	// so every key is matched against the longest matching pattern only
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return len(patterns[i].prefix)+len(patterns[i].suffix) > len(patterns[j].prefix)+len(patterns[j].suffix)
+	})
+
+	seen := make(map[string]bool)
+
+	for _, k := range lister.Keys() {
+		for _, pattern := range patterns {
+			rest, ok := strings.CutPrefix(k, pattern.prefix)
+			if !ok {
+				continue
+			}
+
+			if name, ok := strings.CutSuffix(rest, pattern.suffix); ok && name != "" {
+				seen[name] = true
+				break
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// structKeys lists the mapped keys of struct type t in the scope, including nested structs,
+// but not the keys of nested collections.
+func (p *parser) structKeys(t reflect.Type, s scope) []string {
+	var keys []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		_, custom := p.customTypes[field.Type]
+
+		if st := structType(field.Type); st != nil && !custom {
+			keys = append(keys, p.structKeys(st, s.child(field.Name))...)
+			continue
+		}
+
+		if (p.isStructSlice(field.Type) || p.isStructMap(field.Type)) && !custom {
+			continue
+		}
+
+		if key, ok, _, err := p.tagKey(field.Tag); ok && err == nil {
+			keys = append(keys, p.mapKey(s.names(field.Name), s.prefix+key))
+		}
+	}
+
+	return keys
+}
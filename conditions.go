@@ -0,0 +1,260 @@
+package envset
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	requiredIfTag   = "required_if"
+	requiredWithTag = "required_with"
+	oneOfGroupTag   = "oneof_group"
+	atMostOne       = "at_most_one"
+	exactlyOne      = "exactly_one"
+)
+
+// isConditional tells if the field is required only under some condition.
+func isConditional(tag reflect.StructTag) bool {
+	_, requiredIf := tag.Lookup(requiredIfTag)
+	_, requiredWith := tag.Lookup(requiredWithTag)
+	_, group := tag.Lookup(oneOfGroupTag)
+
+	return requiredIf || requiredWith || group
+}
+
+// checkConditions validates conditional requirements of the populated struct:
+//
+//	`required_if:"TLSEnabled=true"` - required when the TLSEnabled field has value true;
+//	`required_with:"AccessKey,Region"` - required when any of the fields is set.
+//
+// Failed fields were already reported, so they are not checked and the conditions referring to them are skipped.
+func (p *parser) checkConditions(v reflect.Value, s scope, failed map[string]bool) error {
+	var errs Errors
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() || !isConditional(field.Tag) || !v.Field(i).IsZero() || failed[field.Name] {
+			continue
+		}
+
+		if p.collect(&errs, p.checkCondition(v, field, s, failed)) {
+			return errs[0]
+		}
+	}
+
+	if p.collect(&errs, p.checkGroups(v, s, failed)) {
+		return errs[0]
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// checkCondition validates conditional requirement of the unset field.
+func (p *parser) checkCondition(v reflect.Value, field reflect.StructField, s scope, failed map[string]bool) error {
+	key, ok, _, err := p.fieldKey(field, s)
+	if err != nil {
+		return s.fieldError(field.Name, "", err)
+	}
+
+	condition, err := p.condition(v, field.Tag, failed)
+	if err != nil {
+		return s.fieldError(field.Name, key, err)
+	}
+
+	if condition == "" {
+		return nil
+	}
+
+	if !ok {
+		key = s.fieldPath(field.Name)
+	}
+
+	return s.fieldError(field.Name, key, NewConditionalMissingValueError(key, condition))
+}
+
+// group is a set of mutually exclusive alternatives, each alternative is a set of keys.
+type group struct {
+	name         string
+	atMostOne    bool
+	alternatives []string
+	keys         map[string][]string
+	set          map[string][]string
+	failed       bool
+}
+
+// checkGroups validates mutually exclusive groups of fields of the populated struct:
+//
+//	`oneof_group:"auth"` - exactly one field of the auth group must be set;
+//	`oneof_group:"auth:basic"` - fields of the same alternative are counted together;
+//	`oneof_group:"auth,at_most_one"` - none of the fields is required.
+//
+// Groups with a failed field are skipped, as it is unknown whether the field was set.
+func (p *parser) checkGroups(v reflect.Value, s scope, failed map[string]bool) error {
+	var groups []*group
+
+	byName := make(map[string]*group)
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+
+		spec, ok := field.Tag.Lookup(oneOfGroupTag)
+		if !ok || !field.IsExported() {
+			continue
+		}
+
+		spec, policy, _ := strings.Cut(spec, ",")
+		name, alternative, _ := strings.Cut(spec, ":")
+
+		if alternative == "" {
+			alternative = field.Name
+		}
+
+		g, ok := byName[name]
+		if !ok {
+			g = &group{name: name, keys: make(map[string][]string), set: make(map[string][]string)}
+			byName[name] = g
+			groups = append(groups, g)
+		}
+
+		switch policy {
+		case "", exactlyOne:
+		case atMostOne:
+			g.atMostOne = true
+		default:
+			return s.fieldError(field.Name, "", fmt.Errorf("invalid %s policy %q", oneOfGroupTag, policy))
+		}
+
+		key, ok, _, err := p.fieldKey(field, s)
+		if err != nil {
+			return s.fieldError(field.Name, "", err)
+		}
+
+		if !ok {
+			key = s.fieldPath(field.Name)
+		}
+
+		if _, ok := g.keys[alternative]; !ok {
+			g.alternatives = append(g.alternatives, alternative)
+		}
+
+		g.keys[alternative] = append(g.keys[alternative], key)
+
+		if failed[field.Name] {
+			g.failed = true
+		}
+
+		if !v.Field(i).IsZero() {
+			g.set[alternative] = append(g.set[alternative], key)
+		}
+	}
+
+	var errs Errors
+
+	for _, g := range groups {
+		if g.failed {
+			continue
+		}
+
+		if p.collect(&errs, g.check()) {
+			return errs[0]
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (g *group) check() error {
+	if len(g.set) > 1 {
+		var keys []string
+		for _, alternative := range g.alternatives {
+			keys = append(keys, g.set[alternative]...)
+		}
+
+		return GroupError{Group: g.name, Keys: keys}
+	}
+
+	if len(g.set) == 0 && !g.atMostOne {
+		alternatives := make([]string, 0, len(g.alternatives))
+		for _, alternative := range g.alternatives {
+			alternatives = append(alternatives, strings.Join(g.keys[alternative], "+"))
+		}
+
+		return NewConditionalMissingValueError(strings.Join(alternatives, " or "), "in group "+g.name)
+	}
+
+	return nil
+}
+
+// condition returns the description of the condition that makes the field required,
+// or an empty string if the field is not required or the condition refers to a failed field.
+func (p *parser) condition(v reflect.Value, tag reflect.StructTag, failed map[string]bool) (string, error) {
+	if cond, ok := tag.Lookup(requiredIfTag); ok {
+		name, expected, ok := strings.Cut(cond, "=")
+		if !ok {
+			return "", fmt.Errorf("invalid %s condition %q, expected Field=value", requiredIfTag, cond)
+		}
+
+		f, err := sibling(v, name)
+		if err != nil {
+			return "", err
+		}
+
+		if f.IsValid() && !failed[name] && p.valueEquals(f, expected) {
+			return "if " + cond, nil
+		}
+	}
+
+	if cond, ok := tag.Lookup(requiredWithTag); ok {
+		for _, name := range strings.Split(cond, ",") {
+			f, err := sibling(v, strings.TrimSpace(name))
+			if err != nil {
+				return "", err
+			}
+
+			if f.IsValid() && !f.IsZero() && !failed[strings.TrimSpace(name)] {
+				return "with " + strings.TrimSpace(name), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// sibling finds the named field of the struct, dereferencing pointers.
+// Nil pointer results in an invalid value.
+func sibling(v reflect.Value, name string) (reflect.Value, error) {
+	if field, ok := v.Type().FieldByName(name); !ok || !field.IsExported() {
+		return reflect.Value{}, fmt.Errorf("condition refers to unknown field %s", name)
+	}
+
+	f := v.FieldByName(name)
+
+	for f.Kind() == reflect.Pointer {
+		if f.IsNil() {
+			return reflect.Value{}, nil
+		}
+
+		f = f.Elem()
+	}
+
+	return f, nil
+}
+
+// valueEquals compares the resolved value of the field with the value from the condition.
+func (p *parser) valueEquals(f reflect.Value, expected string) bool {
+	if f.Kind() == reflect.Bool {
+		b, ok := p.booleans[strings.ToLower(expected)]
+		return ok && b == f.Bool()
+	}
+
+	return fmt.Sprint(f.Interface()) == expected
+}
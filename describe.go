@@ -0,0 +1,163 @@
+package envset
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	descTag    = "desc"
+	exampleTag = "example"
+)
+
+// fieldInfo describes a tagged field for documentation purposes.
+type fieldInfo struct {
+	// group is the Go path of the struct containing the field
+	group      string
+	path       string
+	key        string
+	typ        reflect.Type
+	tag        reflect.StructTag
+	def        string
+	hasDefault bool
+	optional   bool
+	secret     bool
+	// custom is true if the field type, or its slice elements type, has a custom parser
+	custom bool
+	// separator splits slice values
+	separator string
+}
+
+// required tells if the field must be set, unconditionally.
+func (fi fieldInfo) required() bool {
+	return !fi.optional && !fi.hasDefault
+}
+
+// defaultValue returns the default value, masked for secret fields.
+func (fi fieldInfo) defaultValue() string {
+	if fi.secret && fi.def != "" {
+		return maskedValue
+	}
+
+	return fi.def
+}
+
+// constraints lists the validation tags of the field, e.g. min=1.
+func (fi fieldInfo) constraints() []string {
+	var list []string
+
+	for _, name := range []string{
+		"min", "max", "pattern", enumTag, "len", "minlen", "maxlen", "unique",
+		requiredIfTag, requiredWithTag, oneOfGroupTag, deprecatedTag,
+	} {
+		if val, ok := fi.tag.Lookup(name); ok {
+			list = append(list, name+"="+val)
+		}
+	}
+
+	return list
+}
+
+// placeholders are used in keys of collection elements in place of index or name.
+type placeholders struct {
+	index string
+	name  string
+}
+
+var docPlaceholders = placeholders{index: "<n>", name: "<name>"}
+
+// describe lists the tagged fields of the struct type that Set would visit.
+// Keys of collection elements have placeholders in place of index or name.
+func (p *parser) describe(t reflect.Type, s scope, ph placeholders) ([]fieldInfo, error) {
+	var fields []fieldInfo
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		_, custom := p.customTypes[field.Type]
+
+		if st := structType(field.Type); st != nil && !custom {
+			nested, err := p.describe(st, s.child(field.Name), ph)
+			if err != nil {
+				return nil, err
+			}
+
+			fields = append(fields, nested...)
+
+			continue
+		}
+
+		if (p.isStructSlice(field.Type) || p.isStructMap(field.Type)) && !custom {
+			key, ok, _, err := p.tagKey(field.Tag)
+			if err != nil {
+				return nil, s.fieldError(field.Name, "", err)
+			}
+
+			if !ok {
+				continue
+			}
+
+			placeholder := ph.index
+			if p.isStructMap(field.Type) {
+				placeholder = ph.name
+			}
+
+			es := s.element(field.Name, placeholder, p.indexPrefix(s.prefix+key, placeholder))
+
+			nested, err := p.describe(structType(field.Type.Elem()), es, ph)
+			if err != nil {
+				return nil, err
+			}
+
+			fields = append(fields, nested...)
+
+			continue
+		}
+
+		key, ok, optional, err := p.fieldKey(field, s)
+		if err != nil {
+			return nil, s.fieldError(field.Name, "", err)
+		}
+
+		if !ok {
+			continue
+		}
+
+		def, hasDefault := field.Tag.Lookup(p.defaultTag)
+
+		fields = append(fields, fieldInfo{
+			group:      strings.Join(s.path, "."),
+			path:       s.fieldPath(field.Name),
+			key:        key,
+			typ:        field.Type,
+			tag:        field.Tag,
+			def:        def,
+			hasDefault: hasDefault,
+			optional:   optional,
+			secret:     p.isSecret(field.Tag, key),
+			custom:     custom || field.Type.Kind() == reflect.Slice && p.customTypes[field.Type.Elem()] != nil,
+			separator:  p.sliceSeparator,
+		})
+	}
+
+	return fields, nil
+}
+
+// describeStruct checks the argument and lists its fields.
+func describeStruct(structType reflect.Type, ph placeholders, options []Option) ([]fieldInfo, error) {
+	if structType.Kind() != reflect.Struct {
+		// Same as Set, wrong value passed is a programmatic error
+		panic(ErrStructPtrExpected)
+	}
+
+	return buildParser(options).describe(structType, scope{}, ph)
+}
+
+// typeName returns the name of the field type for documentation.
+func typeName(t reflect.Type) string {
+	return strings.TrimPrefix(fmt.Sprint(t), "*")
+}
@@ -0,0 +1,162 @@
+package envset_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dmytro-vovk/envset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type docsConfig struct {
+	Listen   string        `env:"LISTEN" default:":8080" desc:"Address to listen on"`
+	LogLevel string        `env:"LOG_LEVEL" default:"info" enum:"debug,info,warn,error" desc:"Logging level"`
+	Timeout  time.Duration `env:"TIMEOUT,omitempty" desc:"Request timeout" example:"30s"`
+	DB       struct {
+		Host     string `env:"DB_HOST" desc:"Database host" example:"localhost"`
+		Port     int    `env:"DB_PORT" default:"5432" min:"1" max:"65535"`
+		Password string `env:"DB_PASSWORD" default:"postgres" secret:"true" desc:"Database password"`
+	}
+	Backends []struct {
+		Host string `env:"HOST" pattern:"^[a-z.]+$" desc:"Backend host"`
+	} `env:"BACKEND,omitempty"`
+	private string `env:"PRIVATE"`
+}
+
+func TestUsage(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, envset.Usage(&buf, &docsConfig{}, envset.WithTypeParser(time.ParseDuration)))
+	assert.Equal(t, `VARIABLE          TYPE           DEFAULT  CONSTRAINTS                 DESCRIPTION
+LISTEN            string         :8080                                Address to listen on
+LOG_LEVEL         string         info     enum=debug,info,warn,error  Logging level
+TIMEOUT           time.Duration                                       Request timeout
+DB_HOST           string                  required                    Database host
+DB_PORT           int            5432     min=1 max=65535
+DB_PASSWORD       string         ******                               Database password
+BACKEND_<n>_HOST  string                  required pattern=^[a-z.]+$  Backend host
+`, buf.String())
+}
+
+func TestUsageType(t *testing.T) {
+	var buf bytes.Buffer
+
+	structType := reflect.StructOf([]reflect.StructField{
+		{Name: "Listen", Type: reflect.TypeOf(""), Tag: `env:"{{APP}}_LISTEN" default:":8080"`},
+		{Name: "Token", Type: reflect.TypeOf(""), Tag: `env:"TOKEN" secret:"true" default:"dev"`},
+	})
+
+	require.NoError(t, envset.UsageType(&buf, structType, envset.WithKeyVar("APP", "WEB")))
+	assert.Equal(t, `VARIABLE    TYPE    DEFAULT  CONSTRAINTS  DESCRIPTION
+WEB_LISTEN  string  :8080
+TOKEN       string  ******
+`, buf.String())
+}
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, envset.Markdown(&buf, &docsConfig{}, envset.WithTypeParser(time.ParseDuration)))
+	assert.Equal(t, "| Variable | Type | Required | Default | Constraints | Description | Example |\n"+
+		"|----------|------|----------|---------|-------------|-------------|---------|\n"+
+		"| `LISTEN` | `string` | no | `:8080` |  | Address to listen on |  |\n"+
+		"| `LOG_LEVEL` | `string` | no | `info` | `enum=debug,info,warn,error` | Logging level |  |\n"+
+		"| `TIMEOUT` | `time.Duration` | no |  |  | Request timeout | `30s` |\n"+
+		"\n"+
+		"## DB\n"+
+		"\n"+
+		"| Variable | Type | Required | Default | Constraints | Description | Example |\n"+
+		"|----------|------|----------|---------|-------------|-------------|---------|\n"+
+		"| `DB_HOST` | `string` | yes |  |  | Database host | `localhost` |\n"+
+		"| `DB_PORT` | `int` | no | `5432` | `min=1`, `max=65535` |  |  |\n"+
+		"| `DB_PASSWORD` | `string` | no | `******` |  | Database password |  |\n"+
+		"\n"+
+		"## Backends[&lt;n&gt;]\n"+
+		"\n"+
+		"| Variable | Type | Required | Default | Constraints | Description | Example |\n"+
+		"|----------|------|----------|---------|-------------|-------------|---------|\n"+
+		"| `BACKEND_<n>_HOST` | `string` | yes |  | `pattern=^[a-z.]+$` | Backend host |  |\n",
+		buf.String())
+}
+
+func TestDotEnvExample(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.NoError(t, envset.DotEnvExample(&buf, &docsConfig{}, envset.WithTypeParser(time.ParseDuration)))
+	assert.Equal(t, `# Address to listen on
+LISTEN=:8080
+# Logging level
+LOG_LEVEL=info
+# Request timeout
+# TIMEOUT=
+
+# DB
+# Database host
+DB_HOST=
+DB_PORT=5432
+# Database password
+DB_PASSWORD=
+
+# Backends[0]
+# Backend host
+BACKEND_0_HOST=
+`, buf.String())
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema, err := envset.JSONSchema(&docsConfig{}, envset.WithTypeParser(time.ParseDuration))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"LISTEN": {"type": "string", "description": "Address to listen on", "default": ":8080"},
+			"LOG_LEVEL": {"type": "string", "description": "Logging level", "enum": ["debug", "info", "warn", "error"], "default": "info"},
+			"TIMEOUT": {"type": "string", "description": "Request timeout", "examples": ["30s"]},
+			"DB_HOST": {"type": "string", "description": "Database host", "examples": ["localhost"]},
+			"DB_PORT": {"type": "integer", "minimum": 1, "maximum": 65535, "default": 5432},
+			"DB_PASSWORD": {"type": "string", "description": "Database password", "writeOnly": true}
+		},
+		"patternProperties": {
+			"^BACKEND_[0-9]+_HOST$": {"type": "string", "description": "Backend host", "pattern": "^[a-z.]+$"}
+		},
+		"required": ["DB_HOST"]
+	}`, string(schema))
+
+	type T struct {
+		Ports   []int   `env:"PORTS" default:"80,443" minlen:"1" maxlen:"10" unique:"true" min:"1"`
+		Ratio   float64 `env:"RATIO" default:"0.5"`
+		Debug   bool    `env:"DEBUG" default:"yes"`
+		Code    string  `env:"CODE" len:"2"`
+		Tenants map[string]struct {
+			URL string `env:"URL"`
+		} `env:"TENANT"`
+	}
+
+	schema, err = envset.JSONSchema(&T{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"PORTS": {
+				"type": "array",
+				"items": {"type": "integer", "minimum": 1},
+				"minItems": 1,
+				"maxItems": 10,
+				"uniqueItems": true,
+				"default": [80, 443]
+			},
+			"RATIO": {"type": "number", "default": 0.5},
+			"DEBUG": {"type": "boolean", "default": true},
+			"CODE": {"type": "string", "minLength": 2, "maxLength": 2}
+		},
+		"patternProperties": {
+			"^TENANT_.+_URL$": {"type": "string"}
+		},
+		"required": ["CODE"]
+	}`, string(schema))
+}
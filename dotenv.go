@@ -0,0 +1,69 @@
+package envset
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var examplePlaceholders = placeholders{index: "0", name: "NAME"}
+
+// DotEnvExample writes a .env template for the struct: every key with its default value,
+// descriptions from the `desc` tag as comments, optional keys commented out
+// and values of secret fields left blank. Collections are shown with a single element.
+func DotEnvExample[T any](w io.Writer, structPtr *T, options ...Option) error {
+	fields, err := describeStruct(reflect.TypeOf(structPtr).Elem(), examplePlaceholders, options)
+	if err != nil {
+		return err
+	}
+
+	for i, field := range fields {
+		// Separate nested structs with a blank line and a heading
+		if i == 0 || field.group != fields[i-1].group {
+			if i > 0 {
+				if _, err := io.WriteString(w, "\n"); err != nil {
+					return err
+				}
+			}
+
+			if field.group != "" {
+				if _, err := fmt.Fprintf(w, "# %s\n", field.group); err != nil {
+					return err
+				}
+			}
+		}
+
+		if desc := field.tag.Get(descTag); desc != "" {
+			if _, err := fmt.Fprintf(w, "# %s\n", strings.ReplaceAll(desc, "\n", "\n# ")); err != nil {
+				return err
+			}
+		}
+
+		value := field.def
+		if field.secret {
+			value = ""
+		}
+
+		comment := ""
+		if field.optional {
+			comment = "# "
+		}
+
+		if _, err := fmt.Fprintf(w, "%s%s=%s\n", comment, field.key, dotEnvQuote(value)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dotEnvQuote quotes the value if it has characters needing it.
+func dotEnvQuote(val string) string {
+	if strings.ContainsAny(val, " \t\n\"'#$\\") {
+		return strconv.Quote(val)
+	}
+
+	return val
+}
@@ -0,0 +1,144 @@
+package envset
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Dump renders values of a populated struct keyed by variable names, e.g. to log
+// the effective configuration at startup. Secret values are masked. It implements
+// slog.LogValuer, json.Marshaler and fmt.Stringer.
+type Dump struct {
+	// Fields hold current values of the fields, with the source telling where
+	// the value came from: the source name, SourceDefault, SourceUnset or SourcePreset
+	Fields []FieldReport
+}
+
+// NewDump builds the dump of the struct populated by Set with the same options. Sources of values
+// are taken from the report filled by Set with WithReport, as they are known only while Set runs.
+// Fields missing from the report, e.g. with a nil report, and fields left unset by Set,
+// are reported with SourceUnset if the field has zero value, or SourcePreset otherwise,
+// as the value was then set by the program.
+func NewDump[T any](structPtr *T, report *Report, options ...Option) (*Dump, error) {
+	if reflect.TypeOf(structPtr).Elem().Kind() != reflect.Struct {
+		panic(ErrStructPtrExpected)
+	}
+
+	p := buildParser(options)
+
+	pairs, err := p.marshalPairs(reflect.ValueOf(structPtr).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[string]string)
+
+	if report != nil {
+		for _, field := range report.Fields {
+			sources[field.Path] = field.Source
+		}
+	}
+
+	d := &Dump{Fields: make([]FieldReport, 0, len(pairs))}
+
+	for _, pair := range pairs {
+		field := FieldReport{
+			Path:   pair.path,
+			Key:    pair.key,
+			Source: sources[pair.path],
+			Value:  pair.value,
+			Secret: p.isSecret(pair.field.Tag, pair.key),
+		}
+
+		if field.Source == "" || field.Source == SourceUnset {
+			if pair.zero {
+				field.Source = SourceUnset
+			} else {
+				field.Source = SourcePreset
+			}
+		}
+
+		if field.Secret && field.Value != "" {
+			field.Value = maskedValue
+		}
+
+		d.Fields = append(d.Fields, field)
+	}
+
+	return d, nil
+}
+
+// LogValue groups the fields by key, each with value and source attributes.
+func (d *Dump) LogValue() slog.Value {
+	attrs := make([]slog.Attr, len(d.Fields))
+	for i, field := range d.Fields {
+		attrs[i] = slog.Group(field.Key, slog.String("value", field.Value), slog.String("source", field.Source))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// dumpField is the JSON representation of a field.
+type dumpField struct {
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	Secret bool   `json:"secret,omitempty"`
+}
+
+// MarshalJSON writes an object keyed by variable names, in the order of the fields.
+func (d *Dump) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, field := range d.Fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(field.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := json.Marshal(dumpField{Value: field.Value, Source: field.Source, Secret: field.Secret})
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// String lists the fields in columns of key, value and source.
+// Empty values and values with spaces are quoted.
+func (d *Dump) String() string {
+	var buf bytes.Buffer
+
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+
+	for _, field := range d.Fields {
+		val := field.Value
+		if val == "" || strings.ContainsAny(val, " \t\n\"") {
+			val = strconv.Quote(val)
+		}
+
+		_, _ = io.WriteString(tw, field.Key+"\t"+val+"\t"+field.Source+"\n")
+	}
+
+	_ = tw.Flush()
+
+	return buf.String()
+}
@@ -0,0 +1,53 @@
+package envset
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	enumTag     = "enum"
+	enumFoldTag = "enum_fold"
+)
+
+// checkEnum validates the value against the list of allowed values in the enum tag
+// and returns the matching allowed value. Numeric values are compared as numbers,
+// strings are compared case-insensitively with `enum_fold:"true"` tag.
+func checkEnum(tag reflect.StructTag, val string, numeric bool) (string, error) {
+	enum, ok := tag.Lookup(enumTag)
+	if !ok {
+		return val, nil
+	}
+
+	fold, _ := strconv.ParseBool(tag.Get(enumFoldTag))
+	allowed := strings.Split(enum, ",")
+
+	for i := range allowed {
+		allowed[i] = strings.TrimSpace(allowed[i])
+
+		if enumEquals(allowed[i], val, numeric, fold) {
+			return allowed[i], nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s is not one of %s", ErrInvalidValue, val, strings.Join(allowed, ", "))
+}
+
+func enumEquals(allowed, val string, numeric, fold bool) bool {
+	if numeric {
+		a, errA := strconv.ParseFloat(allowed, 64)
+		v, errV := strconv.ParseFloat(val, 64)
+
+		if errA == nil && errV == nil {
+			return a == v
+		}
+	}
+
+	if fold {
+		return strings.EqualFold(allowed, val)
+	}
+
+	return allowed == val
+}
@@ -3,17 +3,29 @@ package envset
 import (
 	"errors"
 	"fmt"
-	"os"
+	"log/slog"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 type parser struct {
 	sliceSeparator string
 	envTag         string
 	defaultTag     string
+	indexPattern   string
+	source         Source
+	keyVars        map[string]string
+	keyMapper      func(fieldPath []string, key string) string
+	expand         bool
+	allErrors      bool
+	secretKeys     map[string]bool
+	report         *Report
+	logger         *slog.Logger
 	customTypes    map[reflect.Type]func(string) (reflect.Value, error)
+	formatters     map[reflect.Type]func(reflect.Value) string
 	booleans       map[string]bool
 }
 
@@ -21,6 +33,7 @@ const (
 	defaultEnvTag         = "env"
 	defaultDefaultTag     = "default"
 	defaultSliceSeparator = ","
+	defaultIndexPattern   = "{key}_{index}_"
 )
 
 var defaultBooleans = map[string]bool{
@@ -40,7 +53,17 @@ func Set[T any](structPtr *T, options ...Option) error {
 		panic(ErrStructPtrExpected)
 	}
 
-	return buildParser(options).setStruct(reflect.ValueOf(structPtr).Elem())
+	return buildParser(options).setStruct(reflect.ValueOf(structPtr).Elem(), scope{})
+}
+
+// SetValue is Set for a pointer to struct held in reflect.Value,
+// e.g. of a type built at runtime with reflect.StructOf.
+func SetValue(structPtr reflect.Value, options ...Option) error {
+	if structPtr.Kind() != reflect.Pointer || structPtr.Elem().Kind() != reflect.Struct {
+		panic(ErrStructPtrExpected)
+	}
+
+	return buildParser(options).setStruct(structPtr.Elem(), scope{})
 }
 
 func buildParser(options []Option) *parser {
@@ -48,7 +71,12 @@ func buildParser(options []Option) *parser {
 		sliceSeparator: defaultSliceSeparator,
 		envTag:         defaultEnvTag,
 		defaultTag:     defaultDefaultTag,
+		indexPattern:   defaultIndexPattern,
+		source:         environment{},
+		keyVars:        make(map[string]string),
+		secretKeys:     make(map[string]bool),
 		customTypes:    make(map[reflect.Type]func(string) (reflect.Value, error)),
+		formatters:     make(map[reflect.Type]func(reflect.Value) string),
 		booleans:       defaultBooleans,
 	}).apply(options)
 }
@@ -61,77 +89,148 @@ func (p *parser) apply(options []Option) *parser {
 	return p
 }
 
-func (p *parser) setStruct(v reflect.Value) error {
+func (p *parser) setStruct(v reflect.Value, s scope) error {
+	return p.populateStruct(v, s, true)
+}
+
+// populateStruct populates the fields of the struct and checks its conditions,
+// the validator of the struct is called only if validated is set.
+func (p *parser) populateStruct(v reflect.Value, s scope, validated bool) error {
+	var errs Errors
+
+	// Names of the fields which failed, conditions referring to them are not checked
+	failed := make(map[string]bool)
+
 	for i := 0; i < v.Type().NumField(); i++ {
 		// Skip private fields
 		if !v.Type().Field(i).IsExported() {
 			continue
 		}
 
-		f := v.Field(i)
+		err := p.setStructField(v, i, s)
+		p.logError(s, v.Type().Field(i), err)
 
-		// Check if we have a custom type
-		if parser, ok := p.customTypes[f.Type()]; ok {
-			if err := p.parseType(f, v.Type().Field(i).Tag, parser); err != nil {
-				return err
-			}
-			continue
+		if p.collect(&errs, err) {
+			return errs[0]
 		}
 
-		// Check if the field is a struct
-		if f.Type().Kind() == reflect.Struct {
-			if err := p.setStruct(f); err != nil {
-				return err
-			}
-			continue
+		if err != nil {
+			failed[v.Type().Field(i).Name] = true
 		}
+	}
 
-		// Check if the field is a pointer to a struct
-		if f.Kind() == reflect.Pointer && f.Type().Elem().Kind() == reflect.Struct {
-			if f.IsNil() {
-				f.Set(reflect.New(f.Type().Elem()))
-			}
+	// With WithAllErrors the conditions and the validator still run,
+	// so that all the problems are reported at once
+	err := p.checkConditions(v, s, failed)
+	p.logConditions(s, err)
 
-			if err := p.setStruct(f.Elem()); err != nil {
-				return err
-			}
+	if p.collect(&errs, err) {
+		return err
+	}
 
-			continue
+	if validated {
+		if err := validate(v, s); p.collect(&errs, err) {
+			return err
 		}
+	}
 
-		// check if the field already has value
-		if !f.IsZero() {
-			continue
-		}
+	if len(errs) > 0 {
+		return errs
+	}
 
-		// Check if the field is tagged, if not, skip it
-		key, ok, optional := p.tagKey(v.Type().Field(i).Tag)
-		if !ok {
-			continue
+	return nil
+}
+
+// setStructField populates i-th field of the struct.
+func (p *parser) setStructField(v reflect.Value, i int, s scope) error {
+	f := v.Field(i)
+	name := v.Type().Field(i).Name
+
+	// Check if we have a custom type
+	if parser, ok := p.customTypes[f.Type()]; ok {
+		return p.parseType(f, v.Type().Field(i), parser, s)
+	}
+
+	// Check if the field is a struct
+	if f.Type().Kind() == reflect.Struct {
+		return p.populateStruct(f, s.child(name), !promotesValidator(v, v.Type().Field(i)))
+	}
+
+	// Check if the field is a pointer to a struct
+	if f.Kind() == reflect.Pointer && f.Type().Elem().Kind() == reflect.Struct {
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
 		}
 
-		// See if there is an environment variable with name in `key`
-		val, ok := os.LookupEnv(key)
-		if !ok {
-			// Environment var does not exist, check default one
-			if val, ok = v.Type().Field(i).Tag.Lookup(p.defaultTag); !ok {
-				if optional {
-					continue
-				}
-				// No default, not optional, that's an error
-				return NewMissingValueError(key)
+		return p.populateStruct(f.Elem(), s.child(name), !promotesValidator(v, v.Type().Field(i)))
+	}
+
+	// Check if the field is a slice of structs populated from indexed keys
+	if p.isStructSlice(f.Type()) {
+		return p.setStructSlice(f, v.Type().Field(i), s)
+	}
+
+	// Check if the field is a map of structs keyed by a name segment
+	if p.isStructMap(f.Type()) {
+		return p.setStructMap(f, v.Type().Field(i), s)
+	}
+
+	// check if the field already has value
+	if !f.IsZero() {
+		p.recordPreset(s, v.Type().Field(i), f)
+		return nil
+	}
+
+	// Check if the field is tagged, if not, skip it
+	key, ok, optional, err := p.fieldKey(v.Type().Field(i), s)
+	if err != nil {
+		return s.fieldError(name, "", err)
+	}
+
+	if !ok {
+		return nil
+	}
+
+	source := p.sourceName()
+
+	// See if there is an environment variable with name in `key`, or a deprecated one
+	val, ok, err := p.lookup(s, v.Type().Field(i), key)
+	if err != nil {
+		return s.fieldError(name, key, err)
+	}
+
+	if !ok {
+		// Environment var does not exist, check default one
+		if val, ok = v.Type().Field(i).Tag.Lookup(p.defaultTag); !ok {
+			if optional {
+				p.record(s, v.Type().Field(i), key, SourceUnset, "")
+				return nil
 			}
+			// No default, not optional, that's an error
+			return s.fieldError(name, key, NewMissingValueError(key))
 		}
 
-		if val == "" && optional {
-			continue
-		}
+		source = SourceDefault
+	}
 
-		if err := p.setField(f, val, v.Type().Field(i).Tag); err != nil {
-			return err
+	if p.shouldExpand(v.Type().Field(i).Tag) {
+		raw := val
+		if val, err = p.expandValue(val, nil); err != nil {
+			return p.valueError(s, v.Type().Field(i), key, raw, source, err)
 		}
 	}
 
+	if val == "" && optional {
+		p.record(s, v.Type().Field(i), key, SourceUnset, "")
+		return nil
+	}
+
+	if err := p.setField(f, val, v.Type().Field(i).Tag); err != nil {
+		return p.valueError(s, v.Type().Field(i), key, val, source, err)
+	}
+
+	p.record(s, v.Type().Field(i), key, source, val)
+
 	return nil
 }
 
@@ -169,7 +268,7 @@ func (p *parser) setField(f reflect.Value, val string, tags reflect.StructTag) e
 	case reflect.Uint64:
 		return setInteger[uint64](f, tags, val)
 	case reflect.Slice:
-		return parseSlice(f, tags, strings.Split(val, p.sliceSeparator))
+		return p.parseSlice(f, tags, strings.Split(val, p.sliceSeparator))
 	case reflect.String:
 		return p.setString(f, tags, val)
 	default:
@@ -195,55 +294,85 @@ func (p *parser) parseBool(f reflect.Value, val string) error {
 	return nil
 }
 
-func parseSlice(f reflect.Value, tag reflect.StructTag, values []string) error {
-	switch f.Type().Elem().Kind() {
-	case reflect.Float32:
-		return setSliceOfFloats[float32](values, f)
-	case reflect.Float64:
-		return setSliceOfFloats[float64](values, f)
-	case reflect.Int:
-		return setSliceOfIntegers[int](values, f)
-	case reflect.Uint:
-		return setSliceOfIntegers[uint](values, f)
-	case reflect.Int8:
-		return setSliceOfIntegers[int8](values, f)
-	case reflect.Uint8:
-		return setSliceOfIntegers[uint8](values, f)
-	case reflect.Int16:
-		return setSliceOfIntegers[int16](values, f)
-	case reflect.Uint16:
-		return setSliceOfIntegers[uint16](values, f)
-	case reflect.Int32:
-		return setSliceOfIntegers[int32](values, f)
-	case reflect.Uint32:
-		return setSliceOfIntegers[uint32](values, f)
-	case reflect.Int64:
-		return setSliceOfIntegers[int64](values, f)
-	case reflect.Uint64:
-		return setSliceOfIntegers[uint64](values, f)
-	case reflect.String:
-		if pattern, ok := tag.Lookup("pattern"); ok {
-			r, err := regexp.Compile(pattern)
+func (p *parser) parseSlice(f reflect.Value, tag reflect.StructTag, values []string) error {
+	if err := checkLength(tag, len(values)); err != nil {
+		return err
+	}
+
+	elemType := f.Type().Elem()
+	parser, custom := p.customTypes[elemType]
+
+	if !custom && !isSupportedElem(elemType) {
+		return errors.New("unsupported slice elements type: " + elemType.Kind().String())
+	}
+
+	var r *regexp.Regexp
+
+	if pattern, ok := tag.Lookup("pattern"); ok {
+		var err error
+		if r, err = regexp.Compile(pattern); err != nil {
+			return err
+		}
+	}
+
+	// Length constraints apply to the slice, and the pattern is checked against raw values,
+	// the rest of the tags apply to every element.
+	elemTag := withoutTags(tag, "len", "minlen", "maxlen", "pattern")
+	slice := reflect.MakeSlice(f.Type(), len(values), len(values))
+
+	for i := range values {
+		if r != nil && !r.MatchString(values[i]) {
+			return fmt.Errorf("element %d: value %s does not match pattern %s", i, values[i], r)
+		}
+
+		if custom {
+			v, err := parser(values[i])
 			if err != nil {
-				return err
+				return fmt.Errorf("element %d: %w", i, err)
 			}
 
-			for i := range values {
-				if !r.MatchString(values[i]) {
-					return errors.New("value " + values[i] + " does not match pattern " + pattern)
-				}
-			}
+			slice.Index(i).Set(v)
+
+			continue
 		}
 
-		f.Set(reflect.ValueOf(values))
+		if err := p.setField(slice.Index(i), values[i], elemTag); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
 
-		return nil
+	if unique, _ := strconv.ParseBool(tag.Get("unique")); unique {
+		if err := checkUnique(slice); err != nil {
+			return err
+		}
+	}
+
+	f.Set(slice)
+
+	return nil
+}
+
+// isSupportedElem tells if slice elements of type t can be parsed.
+func isSupportedElem(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
 	default:
-		return errors.New("unsupported slice elements type: " + f.Type().Elem().Kind().String())
+		return false
 	}
 }
 
 func (p *parser) setString(f reflect.Value, tag reflect.StructTag, val string) error {
+	if err := checkLength(tag, utf8.RuneCountInString(val)); err != nil {
+		return err
+	}
+
 	if pattern, ok := tag.Lookup("pattern"); ok {
 		r, err := regexp.Compile(pattern)
 		if err != nil {
@@ -255,6 +384,11 @@ func (p *parser) setString(f reflect.Value, tag reflect.StructTag, val string) e
 		}
 	}
 
+	val, err := checkEnum(tag, val, false)
+	if err != nil {
+		return err
+	}
+
 	v := reflect.ValueOf(val)
 	if v.Type() == f.Type() {
 		f.Set(v)
@@ -267,45 +401,71 @@ func (p *parser) setString(f reflect.Value, tag reflect.StructTag, val string) e
 	return nil
 }
 
-func (p *parser) parseType(f reflect.Value, tag reflect.StructTag, parser func(string) (reflect.Value, error)) error {
-	key, ok, optional := p.tagKey(tag)
+func (p *parser) parseType(f reflect.Value, field reflect.StructField, parser func(string) (reflect.Value, error), s scope) error {
+	tag := field.Tag
+
+	key, ok, optional, err := p.fieldKey(field, s)
+	if err != nil {
+		return s.fieldError(field.Name, "", err)
+	}
+
 	if !ok {
 		// No tag, skip it
 		return nil
 	}
 
-	val, ok := os.LookupEnv(key)
+	source := p.sourceName()
+
+	val, ok, err := p.lookup(s, field, key)
+	if err != nil {
+		return s.fieldError(field.Name, key, err)
+	}
+
 	if !ok {
 		// Not set in the environment, check default
 		if val, ok = tag.Lookup(p.defaultTag); !ok {
 			if optional {
+				p.record(s, field, key, SourceUnset, "")
 				return nil
 			}
 			// No default, that's an error
-			return NewMissingValueError(key)
+			return s.fieldError(field.Name, key, NewMissingValueError(key))
+		}
+
+		source = SourceDefault
+	}
+
+	if p.shouldExpand(tag) {
+		raw := val
+		if val, err = p.expandValue(val, nil); err != nil {
+			return p.valueError(s, field, key, raw, source, err)
 		}
 	}
 
 	if val == "" {
 		if optional {
+			p.record(s, field, key, SourceUnset, "")
 			return nil
 		}
 
-		return NewMissingValueError(key)
+		return p.valueError(s, field, key, val, source, NewMissingValueError(key))
 	}
 
 	v, err := parser(val)
-	if err == nil {
-		f.Set(v)
+	if err != nil {
+		return p.valueError(s, field, key, val, source, err)
 	}
 
-	return err
+	f.Set(v)
+	p.record(s, field, key, source, val)
+
+	return nil
 }
 
-func (p *parser) tagKey(tag reflect.StructTag) (key string, exist, optional bool) {
+func (p *parser) tagKey(tag reflect.StructTag) (key string, exist, optional bool, err error) {
 	if key, exist = tag.Lookup(p.envTag); exist {
 		optional = strings.HasSuffix(key, ",omitempty")
-		key = strings.TrimSuffix(key, ",omitempty")
+		key, err = p.expandKey(strings.TrimSuffix(key, ",omitempty"))
 	}
 
 	return
@@ -1,8 +1,17 @@
 package envset_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -169,7 +178,7 @@ func TestOmitEmpty2(t *testing.T) {
 	}
 
 	var v T
-	require.ErrorIs(t, envset.NewMissingValueError("a"), envset.Set(&v))
+	require.ErrorIs(t, envset.Set(&v), envset.NewMissingValueError("a"))
 }
 
 func TestOmitEmptyWithDefault(t *testing.T) {
@@ -281,7 +290,7 @@ func TestString2(t *testing.T) {
 
 	var v T
 
-	require.ErrorIs(t, envset.ErrInvalidValue, envset.Set(&v))
+	require.ErrorIs(t, envset.Set(&v), envset.ErrInvalidValue)
 }
 
 func TestSliceSeparator(t *testing.T) {
@@ -449,3 +458,1263 @@ func TestSkipEnv(t *testing.T) {
 	require.NoError(t, envset.Set(&v))
 	assert.Equal(t, "one", v.A)
 }
+
+func TestSliceOfStructs(t *testing.T) {
+	type Backend struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT" default:"80" min:"1"`
+	}
+	type T struct {
+		Backends []Backend `env:"BACKEND"`
+	}
+
+	t.Setenv("BACKEND_0_HOST", "one")
+	t.Setenv("BACKEND_0_PORT", "8080")
+	t.Setenv("BACKEND_1_HOST", "two")
+	t.Setenv("BACKEND_3_HOST", "not contiguous")
+
+	var v T
+	require.NoError(t, envset.Set(&v))
+	assert.Equal(t, []Backend{{Host: "one", Port: 8080}, {Host: "two", Port: 80}}, v.Backends)
+}
+
+func TestSliceOfStructPointers(t *testing.T) {
+	type Backend struct {
+		Host string `env:"HOST"`
+	}
+	type T struct {
+		Backends []*Backend `env:"UPSTREAM"`
+	}
+
+	t.Setenv("UPSTREAM[0].HOST", "one")
+	t.Setenv("UPSTREAM[1].HOST", "two")
+
+	var v T
+	require.NoError(t, envset.Set(&v, envset.WithIndexPattern("{key}[{index}].")))
+	assert.Equal(t, []*Backend{{Host: "one"}, {Host: "two"}}, v.Backends)
+}
+
+func TestIndexPatternWithoutIndex(t *testing.T) {
+	type Backend struct {
+		Host string `env:"HOST"`
+	}
+	type T struct {
+		Backends []Backend          `env:"BACKEND,omitempty"`
+		Tenants  map[string]Backend `env:"TENANT,omitempty"`
+	}
+
+	src := envset.MapSource{"BACKEND_HOST": "one"}
+
+	var v T
+	err := envset.Set(&v, envset.WithSource(src), envset.WithIndexPattern("{key}_"), envset.WithAllErrors())
+	require.ErrorIs(t, err, envset.ErrIndexPattern)
+	assert.EqualError(t, err, "Backends (BACKEND): index pattern has no {index} placeholder\n"+
+		"Tenants (TENANT): index pattern has no {index} placeholder")
+}
+
+func TestSliceOfCustomStructs(t *testing.T) {
+	type T struct {
+		Times []time.Time `env:"TIMES"`
+	}
+
+	parseTime := func(val string) (time.Time, error) { return time.Parse(time.RFC3339, val) }
+	formatTime := func(val time.Time) string { return val.Format(time.RFC3339) }
+	src := envset.MapSource{"TIMES": "2020-01-01T00:00:00Z,2021-01-01T00:00:00Z"}
+
+	var v T
+	require.NoError(t, envset.Set(&v, envset.WithSource(src), envset.WithTypeParser(parseTime)))
+	assert.Equal(t, []time.Time{
+		time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, v.Times)
+
+	env, err := envset.Marshal(&v, envset.WithTypeParser(parseTime), envset.WithTypeFormatter(formatTime))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TIMES=2020-01-01T00:00:00Z,2021-01-01T00:00:00Z"}, env)
+
+	var usage strings.Builder
+
+	require.NoError(t, envset.Usage(&usage, &v, envset.WithTypeParser(parseTime)))
+	assert.Contains(t, usage.String(), "TIMES     []time.Time")
+}
+
+func TestSliceOfStructsError(t *testing.T) {
+	type Backend struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT" min:"1"`
+	}
+	type T struct {
+		Backends []Backend `env:"BACKEND"`
+	}
+
+	t.Setenv("BACKEND_0_HOST", "one")
+	t.Setenv("BACKEND_0_PORT", "8080")
+	t.Setenv("BACKEND_1_HOST", "two")
+	t.Setenv("BACKEND_1_PORT", "0")
+
+	var v T
+	require.ErrorContains(t, envset.Set(&v), "Backends[1].Port (BACKEND_1_PORT): ")
+
+	t.Setenv("BACKEND_1_PORT", "")
+	require.NoError(t, os.Unsetenv("BACKEND_1_PORT"))
+	require.ErrorIs(t, envset.Set(&v), envset.NewMissingValueError("BACKEND_1_PORT"))
+}
+
+func TestSliceOfStructsMissing(t *testing.T) {
+	type Backend struct {
+		Host string `env:"HOST"`
+	}
+	type T struct {
+		Required []Backend `env:"REQUIRED"`
+	}
+	type O struct {
+		Optional []Backend `env:"OPTIONAL,omitempty"`
+	}
+
+	var v T
+	require.ErrorIs(t, envset.Set(&v), envset.NewMissingValueError("REQUIRED"))
+
+	var o O
+	require.NoError(t, envset.Set(&o))
+	assert.Nil(t, o.Optional)
+}
+
+func TestMapOfStructs(t *testing.T) {
+	type Tenant struct {
+		URL      string `env:"URL"`
+		Token    string `env:"TOKEN,omitempty"`
+		TokenURL string `env:"TOKEN_URL,omitempty"`
+	}
+	type T struct {
+		Tenants map[string]Tenant  `env:"TENANT"`
+		Others  map[string]*Tenant `env:"OTHER,omitempty"`
+	}
+
+	src := envset.MapSource{
+		"TENANT_ACME_URL":        "https://acme",
+		"TENANT_ACME_TOKEN":      "secret",
+		"TENANT_ACME_TOKEN_URL":  "https://acme/token",
+		"TENANT_GLOBEX_CORP_URL": "https://globex",
+		"TENANT_UNRELATED":       "x",
+	}
+
+	var v T
+	require.NoError(t, envset.Set(&v, envset.WithSource(src)))
+	assert.Equal(t, map[string]Tenant{
+		"ACME":        {URL: "https://acme", Token: "secret", TokenURL: "https://acme/token"},
+		"GLOBEX_CORP": {URL: "https://globex"},
+	}, v.Tenants)
+	assert.Nil(t, v.Others)
+}
+
+func TestMapOfStructsNotListable(t *testing.T) {
+	type T struct {
+		Tenants map[string]struct {
+			URL string `env:"URL"`
+		} `env:"TENANT"`
+	}
+
+	var v T
+	require.ErrorIs(t, envset.Set(&v, envset.WithSource(lookupOnly{})), envset.ErrKeysNotListable)
+}
+
+type lookupOnly struct{}
+
+func (lookupOnly) LookupEnv(string) (string, bool) { return "", false }
+
+func TestKeyTemplate(t *testing.T) {
+	type T struct {
+		Port int `env:"{{SERVICE}}_PORT"`
+	}
+
+	t.Setenv("BILLING_PORT", "8081")
+
+	var v T
+	require.NoError(t, envset.Set(&v, envset.WithKeyVar("SERVICE", "BILLING")))
+	assert.Equal(t, 8081, v.Port)
+
+	v = T{}
+	require.ErrorIs(t, envset.Set(&v), envset.ErrUndefinedKeyVar)
+}
+
+func TestKeyMapper(t *testing.T) {
+	type Tenant struct {
+		URL string `env:"url"`
+	}
+	type T struct {
+		Listen string `env:"listen"`
+		Nested struct {
+			Timeout time.Duration `env:"timeout"`
+		}
+		Backends []struct {
+			Host string `env:"host"`
+		} `env:"backend"`
+		Tenants map[string]Tenant `env:"tenant"`
+	}
+
+	src := envset.MapSource{
+		"APP_LISTEN":          ":8080",
+		"APP_TIMEOUT":         "1s",
+		"APP_BACKEND_0_HOST":  "one",
+		"APP_TENANT_ACME_URL": "https://acme",
+	}
+
+	var paths [][]string
+
+	var v T
+	require.NoError(t, envset.Set(&v,
+		envset.WithSource(src),
+		envset.WithTypeParser(time.ParseDuration),
+		envset.WithKeyMapper(func(fieldPath []string, key string) string {
+			paths = append(paths, fieldPath)
+			return "APP_" + strings.ToUpper(key)
+		}),
+	))
+
+	assert.Equal(t, ":8080", v.Listen)
+	assert.Equal(t, time.Second, v.Nested.Timeout)
+	require.Len(t, v.Backends, 1)
+	assert.Equal(t, "one", v.Backends[0].Host)
+	assert.Equal(t, map[string]Tenant{"ACME": {URL: "https://acme"}}, v.Tenants)
+	assert.Contains(t, paths, []string{"Nested", "Timeout"})
+	assert.Contains(t, paths, []string{"Backends[0]", "Host"})
+}
+
+func TestExpansion(t *testing.T) {
+	type T struct {
+		Addr    string `env:"ADDR" default:"${HOST}:${PORT:-8080}" expand:"true"`
+		DataDir string `env:"DATA_DIR" expand:"true"`
+		Price   string `env:"PRICE" default:"$$5 ${NOPE}"`
+		Raw     string `env:"RAW" default:"${HOST}"`
+	}
+
+	src := envset.MapSource{
+		"HOST":     "localhost",
+		"HOME":     "/home/${USER}",
+		"USER":     "me",
+		"DATA_DIR": "${HOME}/data",
+	}
+
+	var v T
+	require.NoError(t, envset.Set(&v, envset.WithSource(src)))
+	assert.Equal(t, T{Addr: "localhost:8080", DataDir: "/home/me/data", Price: "$$5 ${NOPE}", Raw: "${HOST}"}, v)
+
+	v = T{}
+	require.NoError(t, envset.Set(&v, envset.WithSource(src), envset.WithExpansion()))
+	assert.Equal(t, T{Addr: "localhost:8080", DataDir: "/home/me/data", Price: "$5 ", Raw: "localhost"}, v)
+}
+
+func TestExpansionErrors(t *testing.T) {
+	type Required struct {
+		A string `env:"A" default:"${NAME:?name is required}"`
+	}
+	type Cycle struct {
+		A string `env:"A" default:"${X}"`
+	}
+	type Unterminated struct {
+		A string `env:"A" default:"${X"`
+	}
+
+	var r Required
+	err := envset.Set(&r, envset.WithSource(envset.MapSource{}), envset.WithExpansion())
+	require.ErrorIs(t, err, envset.ErrUnsetVariable)
+	require.ErrorContains(t, err, "name is required")
+
+	var c Cycle
+	err = envset.Set(&c, envset.WithSource(envset.MapSource{"X": "${Y}", "Y": "a${X}"}), envset.WithExpansion())
+	require.ErrorIs(t, err, envset.ErrExpansionCycle)
+	require.ErrorContains(t, err, "X -> Y -> X")
+
+	var u Unterminated
+	require.ErrorIs(t, envset.Set(&u, envset.WithSource(envset.MapSource{}), envset.WithExpansion()), envset.ErrInvalidValue)
+}
+
+func TestRequiredIf(t *testing.T) {
+	type T struct {
+		TLSEnabled bool   `env:"TLS_ENABLED" default:"false"`
+		TLSKeyFile string `env:"TLS_KEY_FILE" required_if:"TLSEnabled=true"`
+	}
+
+	var v T
+	require.NoError(t, envset.Set(&v, envset.WithSource(envset.MapSource{})))
+
+	v = T{}
+	err := envset.Set(&v, envset.WithSource(envset.MapSource{"TLS_ENABLED": "yes"}))
+	require.ErrorIs(t, err, envset.NewConditionalMissingValueError("TLS_KEY_FILE", "if TLSEnabled=true"))
+	require.EqualError(t, err, "TLSKeyFile (TLS_KEY_FILE): value required if TLSEnabled=true, but not set: TLS_KEY_FILE")
+
+	v = T{}
+	require.NoError(t, envset.Set(&v, envset.WithSource(envset.MapSource{"TLS_ENABLED": "yes", "TLS_KEY_FILE": "key.pem"})))
+	assert.Equal(t, "key.pem", v.TLSKeyFile)
+}
+
+func TestRequiredWith(t *testing.T) {
+	type T struct {
+		S3AccessKey *string `env:"S3_ACCESS_KEY,omitempty"`
+		S3Secret    string  `env:"S3_SECRET,omitempty" required_with:"S3AccessKey"`
+	}
+
+	var v T
+	require.NoError(t, envset.Set(&v, envset.WithSource(envset.MapSource{})))
+
+	v = T{}
+	require.ErrorIs(t,
+		envset.Set(&v, envset.WithSource(envset.MapSource{"S3_ACCESS_KEY": "key"})),
+		envset.NewConditionalMissingValueError("S3_SECRET", "with S3AccessKey"),
+	)
+}
+
+func TestRequiredIfUnknownField(t *testing.T) {
+	type T struct {
+		A string `env:"A" required_if:"Nope=1"`
+	}
+
+	var v T
+	require.ErrorContains(t, envset.Set(&v, envset.WithSource(envset.MapSource{})), "unknown field Nope")
+}
+
+func TestOneOfGroup(t *testing.T) {
+	type T struct {
+		APIToken string `env:"API_TOKEN" oneof_group:"auth"`
+		Username string `env:"USERNAME" oneof_group:"auth:basic"`
+		Password string `env:"PASSWORD" oneof_group:"auth:basic"`
+	}
+
+	var v T
+	require.NoError(t, envset.Set(&v, envset.WithSource(envset.MapSource{"API_TOKEN": "token"})))
+	assert.Equal(t, "token", v.APIToken)
+
+	v = T{}
+	require.NoError(t, envset.Set(&v, envset.WithSource(envset.MapSource{"USERNAME": "user", "PASSWORD": "pass"})))
+
+	v = T{}
+	err := envset.Set(&v, envset.WithSource(envset.MapSource{"API_TOKEN": "token", "PASSWORD": "pass"}))
+
+	var groupErr envset.GroupError
+	require.ErrorAs(t, err, &groupErr)
+	assert.Equal(t, envset.GroupError{Group: "auth", Keys: []string{"API_TOKEN", "PASSWORD"}}, groupErr)
+
+	v = T{}
+	require.EqualError(t,
+		envset.Set(&v, envset.WithSource(envset.MapSource{})),
+		"value required in group auth, but not set: API_TOKEN or USERNAME+PASSWORD",
+	)
+}
+
+func TestOneOfGroupAtMostOne(t *testing.T) {
+	type T struct {
+		A string `env:"A" oneof_group:"g,at_most_one"`
+		B string `env:"B" oneof_group:"g"`
+		C string `env:"C" oneof_group:"h,whatever"`
+	}
+
+	type U struct {
+		A string `env:"A" oneof_group:"g,at_most_one"`
+		B string `env:"B" oneof_group:"g"`
+	}
+
+	var u U
+	require.NoError(t, envset.Set(&u, envset.WithSource(envset.MapSource{})))
+	require.ErrorAs(t, envset.Set(&u, envset.WithSource(envset.MapSource{"A": "a", "B": "b"})), new(envset.GroupError))
+
+	var v T
+	require.ErrorContains(t, envset.Set(&v, envset.WithSource(envset.MapSource{})), `invalid oneof_group policy "whatever"`)
+}
+
+func TestEnum(t *testing.T) {
+	type T struct {
+		Level  string    `env:"LEVEL" enum:"debug,info,warn,error"`
+		Mode   string    `env:"MODE" enum:"fast, slow" enum_fold:"true"`
+		Shards int       `env:"SHARDS" enum:"1,2,4,8"`
+		Ratio  float64   `env:"RATIO" enum:"0.5,1"`
+		Levels []string  `env:"LEVELS" enum:"debug,info" enum_fold:"true"`
+		Sizes  []float32 `env:"SIZES" enum:"1.5,2.5"`
+	}
+
+	src := envset.MapSource{
+		"LEVEL":  "warn",
+		"MODE":   "FAST",
+		"SHARDS": "4",
+		"RATIO":  "1.0",
+		"LEVELS": "DEBUG,info",
+		"SIZES":  "2.5,1.5",
+	}
+
+	var v T
+	require.NoError(t, envset.Set(&v, envset.WithSource(src)))
+	assert.Equal(t, T{
+		Level:  "warn",
+		Mode:   "fast",
+		Shards: 4,
+		Ratio:  1,
+		Levels: []string{"debug", "info"},
+		Sizes:  []float32{2.5, 1.5},
+	}, v)
+
+	for key, val := range map[string]string{
+		"LEVEL":  "WARN",
+		"SHARDS": "3",
+		"RATIO":  "2",
+		"LEVELS": "info,trace",
+		"SIZES":  "1",
+	} {
+		t.Run(key, func(t *testing.T) {
+			invalid := envset.MapSource{}
+			for k, v := range src {
+				invalid[k] = v
+			}
+			invalid[key] = val
+
+			var v T
+			err := envset.Set(&v, envset.WithSource(invalid))
+			require.ErrorIs(t, err, envset.ErrInvalidValue)
+			require.ErrorContains(t, err, "is not one of")
+		})
+	}
+
+	var v2 T
+	src["LEVEL"] = "trace"
+	require.EqualError(t, envset.Set(&v2, envset.WithSource(src)), "Level (LEVEL): invalid value: trace is not one of debug, info, warn, error")
+}
+
+func TestLength(t *testing.T) {
+	type T struct {
+		Key   string   `env:"KEY" minlen:"4" maxlen:"6"`
+		Code  string   `env:"CODE" len:"2"`
+		Hosts []string `env:"HOSTS" minlen:"1" maxlen:"3"`
+		Ports []int    `env:"PORTS" len:"2"`
+	}
+
+	src := envset.MapSource{
+		"KEY":   "ключ",
+		"CODE":  "ua",
+		"HOSTS": "a,b,c",
+		"PORTS": "80,443",
+	}
+
+	var v T
+	require.NoError(t, envset.Set(&v, envset.WithSource(src)))
+	assert.Equal(t, T{Key: "ключ", Code: "ua", Hosts: []string{"a", "b", "c"}, Ports: []int{80, 443}}, v)
+
+	for key, tc := range map[string]struct{ val, err string }{
+		"KEY":   {val: "abc", err: "length 3 is less than the minimal length 4"},
+		"CODE":  {val: "ukr", err: "length 3 is not equal to the length 2"},
+		"HOSTS": {val: "a,b,c,d", err: "length 4 is greater than the maximal length 3"},
+		"PORTS": {val: "80", err: "length 1 is not equal to the length 2"},
+	} {
+		t.Run(key, func(t *testing.T) {
+			invalid := envset.MapSource{}
+			for k, v := range src {
+				invalid[k] = v
+			}
+			invalid[key] = tc.val
+
+			var v T
+			err := envset.Set(&v, envset.WithSource(invalid))
+			require.ErrorIs(t, err, envset.ErrInvalidValue)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestSliceElements(t *testing.T) {
+	type T struct {
+		Ports     []int           `env:"PORTS" min:"1" max:"65535" unique:"true"`
+		Weights   []float64       `env:"WEIGHTS" min:"0" max:"1"`
+		Codes     []uint16        `env:"CODES" pattern:"^[0-9]{3}$"`
+		Flags     []bool          `env:"FLAGS"`
+		Names     []*string       `env:"NAMES" enum:"a,b"`
+		Intervals []time.Duration `env:"INTERVALS"`
+	}
+
+	src := envset.MapSource{
+		"PORTS":     "80,443",
+		"WEIGHTS":   "0.5,1",
+		"CODES":     "200,404",
+		"FLAGS":     "y,n",
+		"NAMES":     "a,b,a",
+		"INTERVALS": "1s,1m",
+	}
+
+	var v T
+	require.NoError(t, envset.Set(&v, envset.WithSource(src), envset.WithTypeParser(time.ParseDuration)))
+	assert.Equal(t, T{
+		Ports:     []int{80, 443},
+		Weights:   []float64{0.5, 1},
+		Codes:     []uint16{200, 404},
+		Flags:     []bool{true, false},
+		Names:     []*string{ptr("a"), ptr("b"), ptr("a")},
+		Intervals: []time.Duration{time.Second, time.Minute},
+	}, v)
+
+	for key, tc := range map[string]struct{ val, err string }{
+		"PORTS":     {val: "80,0", err: "element 1: value 0 is less than the minimal value 1"},
+		"WEIGHTS":   {val: "0.5,1.5", err: "element 1: value 1.5 is greater than the maximal value 1"},
+		"CODES":     {val: "200,4040", err: "element 1: value 4040 does not match pattern ^[0-9]{3}$"},
+		"NAMES":     {val: "a,c", err: "element 1: invalid value: c is not one of a, b"},
+		"INTERVALS": {val: "1s,1x", err: "element 1: time: unknown unit"},
+	} {
+		t.Run(key, func(t *testing.T) {
+			invalid := envset.MapSource{}
+			for k, v := range src {
+				invalid[k] = v
+			}
+			invalid[key] = tc.val
+
+			var v T
+			require.ErrorContains(t, envset.Set(&v, envset.WithSource(invalid), envset.WithTypeParser(time.ParseDuration)), tc.err)
+		})
+	}
+
+	src["PORTS"] = "80,443,80"
+	v = T{}
+	err := envset.Set(&v, envset.WithSource(src), envset.WithTypeParser(time.ParseDuration))
+	require.ErrorIs(t, err, envset.ErrInvalidValue)
+	require.ErrorContains(t, err, "element 2 duplicates element 0")
+}
+
+func TestMax(t *testing.T) {
+	type T struct {
+		I int     `env:"I" max:"10"`
+		F float32 `env:"F" max:"1.5"`
+	}
+
+	var v T
+	require.NoError(t, envset.Set(&v, envset.WithSource(envset.MapSource{"I": "10", "F": "1.5"})))
+
+	v = T{}
+	require.ErrorContains(t, envset.Set(&v, envset.WithSource(envset.MapSource{"I": "11", "F": "1"})), "greater than the maximal value 10")
+
+	v = T{}
+	require.ErrorContains(t, envset.Set(&v, envset.WithSource(envset.MapSource{"I": "1", "F": "2"})), "greater than the maximal value 1.5")
+}
+
+type pool struct {
+	Min int `env:"MIN"`
+	Max int `env:"MAX"`
+}
+
+func (p *pool) Validate() error {
+	if p.Min > p.Max {
+		return errors.New("min is greater than max")
+	}
+
+	return nil
+}
+
+type ports struct {
+	HTTP  int `env:"HTTP_PORT"`
+	Admin int `env:"ADMIN_PORT"`
+	Pool  struct {
+		DB pool
+	}
+	Backends []pool `env:"BACKEND,omitempty"`
+}
+
+func (p ports) Validate() error {
+	if p.HTTP == p.Admin {
+		return errors.New("ports must be distinct")
+	}
+
+	return nil
+}
+
+func TestValidator(t *testing.T) {
+	src := envset.MapSource{
+		"HTTP_PORT":  "80",
+		"ADMIN_PORT": "81",
+		"MIN":        "1",
+		"MAX":        "10",
+	}
+
+	var v ports
+	require.NoError(t, envset.Set(&v, envset.WithSource(src)))
+
+	src["ADMIN_PORT"] = "80"
+	v = ports{}
+	require.EqualError(t, envset.Set(&v, envset.WithSource(src)), "ports must be distinct")
+
+	src["ADMIN_PORT"] = "81"
+	src["MIN"] = "11"
+	v = ports{}
+	require.EqualError(t, envset.Set(&v, envset.WithSource(src)), "Pool.DB: min is greater than max")
+
+	src["MIN"] = "1"
+	src["BACKEND_0_MIN"] = "1"
+	src["BACKEND_0_MAX"] = "1"
+	src["BACKEND_1_MIN"] = "2"
+	src["BACKEND_1_MAX"] = "1"
+	v = ports{}
+	require.EqualError(t, envset.Set(&v, envset.WithSource(src)), "Backends[1]: min is greater than max")
+}
+
+type Counted struct {
+	N     int `env:"N"`
+	calls *int
+}
+
+func (c *Counted) Validate() error {
+	*c.calls++
+	return nil
+}
+
+type Shadowing struct {
+	Counted
+	M int `env:"M"`
+}
+
+func (s *Shadowing) Validate() error {
+	if s.M < s.N {
+		return errors.New("M is less than N")
+	}
+
+	return nil
+}
+
+func TestValidatorEmbedded(t *testing.T) {
+	src := envset.MapSource{"N": "2", "M": "1"}
+
+	var calls int
+
+	v := struct{ Counted }{Counted{calls: &calls}}
+	require.NoError(t, envset.Set(&v, envset.WithSource(src)))
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	w := struct{ *Counted }{&Counted{calls: &calls}}
+	require.NoError(t, envset.Set(&w, envset.WithSource(src)))
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	s := Shadowing{Counted: Counted{calls: &calls}}
+	require.EqualError(t, envset.Set(&s, envset.WithSource(src)), "M is less than N")
+	assert.Equal(t, 0, calls)
+}
+
+func TestAllErrors(t *testing.T) {
+	type T struct {
+		A     string `env:"A"`
+		B     int    `env:"B"`
+		C     string `env:"C" pattern:"^c$"`
+		Inner struct {
+			D bool `env:"D"`
+		}
+		Items []struct {
+			E int `env:"E" min:"1"`
+		} `env:"ITEM"`
+		F string `env:"F,omitempty" required_with:"C"`
+	}
+
+	src := envset.MapSource{
+		"B":        "b",
+		"C":        "x",
+		"D":        "maybe",
+		"ITEM_0_E": "0",
+		"ITEM_1_E": "1",
+		"ITEM_2_E": "-1",
+	}
+
+	var v T
+	require.ErrorIs(t, envset.Set(&v, envset.WithSource(src)), envset.NewMissingValueError("A"))
+
+	v = T{}
+	err := envset.Set(&v, envset.WithSource(src), envset.WithAllErrors())
+
+	var errs envset.Errors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 6)
+	assert.ErrorIs(t, err, envset.NewMissingValueError("A"))
+	assert.ErrorIs(t, err, envset.ErrInvalidValue)
+	assert.ErrorContains(t, errs[1], "invalid syntax")
+	assert.ErrorContains(t, errs[3], "invalid bool value")
+	assert.ErrorContains(t, errs[4], "Items[0].E (ITEM_0_E): ")
+	assert.ErrorContains(t, errs[5], "Items[2].E (ITEM_2_E): ")
+
+	src = envset.MapSource{"A": "a", "B": "1", "C": "c", "D": "y", "ITEM_0_E": "1"}
+	v = T{}
+	err = envset.Set(&v, envset.WithSource(src), envset.WithAllErrors())
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, err, envset.NewConditionalMissingValueError("F", "with C"))
+}
+
+type allErrorsConfig struct {
+	Port       int    `env:"PORT"`
+	TLSEnabled bool   `env:"TLS_ENABLED"`
+	TLSKeyFile string `env:"TLS_KEY_FILE,omitempty" required_if:"TLSEnabled=true"`
+	Debug      string `env:"DEBUG,omitempty" required_if:"Port=0"`
+	Name       string `env:"NAME,omitempty"`
+}
+
+func (c allErrorsConfig) Validate() error {
+	if c.Name == "" {
+		return errors.New("name is not set")
+	}
+
+	return nil
+}
+
+func TestAllErrorsConditions(t *testing.T) {
+	src := envset.MapSource{"PORT": "x", "TLS_ENABLED": "yes"}
+
+	var v allErrorsConfig
+	err := envset.Set(&v, envset.WithSource(src), envset.WithAllErrors())
+
+	var errs envset.Errors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 3)
+	assert.ErrorContains(t, errs[0], "Port (PORT): ")
+	assert.ErrorIs(t, errs[1], envset.NewConditionalMissingValueError("TLS_KEY_FILE", "if TLSEnabled=true"))
+	assert.EqualError(t, errs[2], "name is not set")
+
+	v = allErrorsConfig{}
+	err = envset.Set(&v, envset.WithSource(src))
+
+	var fieldErr *envset.FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Port", fieldErr.Path)
+}
+
+func TestFieldError(t *testing.T) {
+	type T struct {
+		Nested struct {
+			Port int `env:"PORT" min:"1"`
+		}
+		Flag bool `env:"FLAG" default:"maybe"`
+	}
+
+	var v T
+	err := envset.Set(&v, envset.WithSource(envset.MapSource{"PORT": "0"}), envset.WithAllErrors())
+
+	var errs envset.Errors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 2)
+
+	var fieldErr *envset.FieldError
+	require.ErrorAs(t, errs[0], &fieldErr)
+	assert.Equal(t, "Nested.Port", fieldErr.Path)
+	assert.Equal(t, "PORT", fieldErr.Key)
+	assert.Equal(t, "0", fieldErr.Value)
+	assert.Equal(t, "map", fieldErr.Source)
+	assert.EqualError(t, fieldErr.Err, "value 0 is less than the minimal value 1")
+
+	require.ErrorAs(t, errs[1], &fieldErr)
+	assert.Equal(t, "Flag", fieldErr.Path)
+	assert.Equal(t, "maybe", fieldErr.Value)
+	assert.Equal(t, "default", fieldErr.Source)
+
+	v = T{}
+	err = envset.Set(&v, envset.WithSource(envset.MapSource{}))
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, &envset.FieldError{Path: "Nested.Port", Key: "PORT", Err: envset.NewMissingValueError("PORT")}, fieldErr)
+}
+
+func TestSecret(t *testing.T) {
+	type T struct {
+		Password string   `env:"PASSWORD" minlen:"12" secret:"true"`
+		Enabled  bool     `env:"ENABLED"`
+		Tokens   []string `env:"TOKENS" pattern:"^tk-" secret:"true"`
+	}
+
+	src := envset.MapSource{"PASSWORD": "hunter2", "ENABLED": "s3cr3t", "TOKENS": "tk-one,leaked"}
+
+	var v T
+	err := envset.Set(&v, envset.WithSource(src), envset.WithAllErrors(), envset.WithSecretKeys("ENABLED"))
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "hunter2")
+	assert.NotContains(t, err.Error(), "s3cr3t")
+	assert.NotContains(t, err.Error(), "leaked")
+	assert.Contains(t, err.Error(), "ENABLED): invalid value (redacted)")
+	assert.ErrorIs(t, err, envset.ErrInvalidValue)
+
+	var fieldErr *envset.FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Password", fieldErr.Path)
+	assert.Empty(t, fieldErr.Value)
+	assert.True(t, fieldErr.Redacted)
+}
+
+func TestSecretQuoted(t *testing.T) {
+	type T struct {
+		Password string `env:"PW" secret:"true" expand:"true"`
+		Port     int    `env:"PORT" secret:"true"`
+		Missing  string `env:"MISSING" secret:"true"`
+	}
+
+	for name, tc := range map[string]struct {
+		src     envset.MapSource
+		message string
+		target  error
+	}{
+		"expansion": {
+			src:     envset.MapSource{"PW": "s3\"cr\tet${X", "PORT": "1"},
+			message: "Password (PW): invalid value (redacted)",
+			target:  envset.ErrInvalidValue,
+		},
+		"number": {
+			src:     envset.MapSource{"PW": "pw", "PORT": "s3\"cr\tet"},
+			message: "Port (PORT): invalid value (redacted)",
+			target:  strconv.ErrSyntax,
+		},
+		"missing": {
+			src:     envset.MapSource{"PW": "pw", "PORT": "1"},
+			message: "Missing (MISSING): value required, but not set: MISSING",
+			target:  envset.MissingValueError{},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var v T
+
+			err := envset.Set(&v, envset.WithSource(tc.src))
+			require.Error(t, err)
+			assert.Equal(t, tc.message, err.Error())
+			assert.NotContains(t, err.Error(), "cr")
+
+			if target, ok := tc.target.(envset.MissingValueError); ok {
+				assert.ErrorAs(t, err, &target)
+			} else {
+				assert.ErrorIs(t, err, tc.target)
+			}
+
+			// No error in the chain reveals the value
+			for _, err := range unwrapAll(err) {
+				assert.NotContains(t, err.Error(), "cr")
+				assert.NotContains(t, fmt.Sprintf("%#v", err), "cr")
+			}
+
+			var numErr *strconv.NumError
+			assert.False(t, errors.As(err, &numErr))
+		})
+	}
+}
+
+// unwrapAll lists the error and all the errors it wraps.
+func unwrapAll(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	list := []error{err}
+
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() error }:
+		list = append(list, unwrapAll(wrapped.Unwrap())...)
+	case interface{ Unwrap() []error }:
+		for _, err := range wrapped.Unwrap() {
+			list = append(list, unwrapAll(err)...)
+		}
+	}
+
+	return list
+}
+
+func TestReport(t *testing.T) {
+	type T struct {
+		Host     string        `env:"DB_HOST"`
+		Port     int           `env:"DB_PORT" default:"5432"`
+		User     string        `env:"DB_USER"`
+		Password string        `env:"DB_PASSWORD" secret:"true"`
+		Timeout  time.Duration `env:"TIMEOUT,omitempty"`
+		Replicas []struct {
+			Host string `env:"HOST"`
+		} `env:"REPLICA,omitempty"`
+	}
+
+	src := envset.MapSource{"DB_HOST": "db", "DB_PASSWORD": "hunter2"}
+
+	var (
+		v      = T{User: "admin"}
+		report envset.Report
+	)
+
+	require.NoError(t, envset.Set(&v,
+		envset.WithSource(src),
+		envset.WithTypeParser(time.ParseDuration),
+		envset.WithReport(&report),
+	))
+
+	assert.Equal(t, []envset.FieldReport{
+		{Path: "Host", Key: "DB_HOST", Source: "map", Value: "db"},
+		{Path: "Port", Key: "DB_PORT", Source: envset.SourceDefault, Value: "5432"},
+		{Path: "User", Key: "DB_USER", Source: envset.SourcePreset, Value: "admin"},
+		{Path: "Password", Key: "DB_PASSWORD", Source: "map", Value: "******", Secret: true},
+		{Path: "Timeout", Key: "TIMEOUT", Source: envset.SourceUnset},
+		{Path: "Replicas", Key: "REPLICA", Source: envset.SourceUnset},
+	}, report.Fields)
+
+	field, ok := report.Field("Port")
+	require.True(t, ok)
+	assert.Equal(t, envset.SourceDefault, field.Source)
+}
+
+func TestReportNil(t *testing.T) {
+	type T struct {
+		Host string `env:"DB_HOST"`
+	}
+
+	var v T
+	require.NoError(t, envset.Set(&v, envset.WithSource(envset.MapSource{"DB_HOST": "db"}), envset.WithReport(nil)))
+	assert.Equal(t, "db", v.Host)
+}
+
+func TestMarshal(t *testing.T) {
+	type Backend struct {
+		Host string `env:"HOST"`
+		Port uint16 `env:"PORT"`
+	}
+
+	type T struct {
+		Name     string             `env:"NAME"`
+		Debug    bool               `env:"DEBUG"`
+		Ratio    float32            `env:"RATIO"`
+		Limit    *int               `env:"LIMIT,omitempty"`
+		Tags     []string           `env:"TAGS,omitempty"`
+		Retries  []time.Duration    `env:"RETRIES"`
+		Timeout  time.Duration      `env:"TIMEOUT"`
+		Price    string             `env:"PRICE" expand:"true"`
+		Backends []Backend          `env:"BACKEND"`
+		Tenants  map[string]Backend `env:"TENANT,omitempty"`
+		DB       struct {
+			Password string `env:"DB_PASSWORD" secret:"true"`
+		}
+	}
+
+	limit := 10
+	v := T{
+		Name:     "app",
+		Debug:    true,
+		Ratio:    0.1,
+		Limit:    &limit,
+		Retries:  []time.Duration{time.Second, time.Minute},
+		Timeout:  time.Second / 2,
+		Price:    "$5",
+		Backends: []Backend{{Host: "a", Port: 80}, {Host: "b", Port: 8080}},
+		Tenants:  map[string]Backend{"ACME": {Host: "acme"}},
+	}
+	v.DB.Password = "hunter2"
+
+	options := []envset.Option{
+		envset.WithSliceSeparator(";"),
+		envset.WithTypeParser(time.ParseDuration),
+		envset.WithTypeFormatter(time.Duration.String),
+	}
+
+	env, err := envset.Marshal(&v, options...)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"NAME=app",
+		"DEBUG=true",
+		"RATIO=0.1",
+		"LIMIT=10",
+		"RETRIES=1s;1m0s",
+		"TIMEOUT=500ms",
+		"PRICE=$$5",
+		"BACKEND_0_HOST=a",
+		"BACKEND_0_PORT=80",
+		"BACKEND_1_HOST=b",
+		"BACKEND_1_PORT=8080",
+		"TENANT_ACME_HOST=acme",
+		"TENANT_ACME_PORT=0",
+		"DB_PASSWORD=hunter2",
+	}, env)
+
+	m, err := envset.MarshalMap(&v, options...)
+	require.NoError(t, err)
+	assert.Len(t, m, len(env))
+
+	var got T
+
+	require.NoError(t, envset.Set(&got, append(options, envset.WithSource(envset.MapSource(m)))...))
+	assert.Equal(t, v, got)
+}
+
+func TestMarshalErrors(t *testing.T) {
+	type T struct {
+		Tags []string `env:"TAGS"`
+		Keys []string `env:"KEYS" secret:"true"`
+	}
+
+	_, err := envset.Marshal(&T{Tags: []string{"a,b"}})
+
+	var fieldErr *envset.FieldError
+
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Tags (TAGS): element 0 contains the slice separator ,", err.Error())
+
+	_, err = envset.Marshal(&T{Tags: []string{"a"}, Keys: []string{"hunter2,topsecret"}})
+	require.ErrorAs(t, err, &fieldErr)
+	assert.True(t, fieldErr.Redacted)
+	assert.NotContains(t, err.Error(), "hunter2")
+}
+
+func TestDump(t *testing.T) {
+	type T struct {
+		Host     string        `env:"DB_HOST"`
+		Port     int           `env:"DB_PORT" default:"5432"`
+		User     string        `env:"DB_USER"`
+		Password string        `env:"DB_PASSWORD" secret:"true"`
+		Timeout  time.Duration `env:"TIMEOUT,omitempty"`
+		Name     string        `env:"NAME,omitempty"`
+		Tags     []string      `env:"TAGS,omitempty"`
+	}
+
+	options := []envset.Option{
+		envset.WithSource(envset.MapSource{"DB_HOST": "db host", "DB_PASSWORD": "hunter2"}),
+		envset.WithTypeParser(time.ParseDuration),
+	}
+
+	var report envset.Report
+
+	v := T{User: "admin"}
+	require.NoError(t, envset.Set(&v, append(options, envset.WithReport(&report))...))
+
+	// Elements with the separator do not round-trip, but are fine to log
+	v.Tags = []string{"a,b"}
+
+	dump, err := envset.NewDump(&v, &report, options...)
+	require.NoError(t, err)
+
+	assert.Equal(t, []envset.FieldReport{
+		{Path: "Host", Key: "DB_HOST", Source: "map", Value: "db host"},
+		{Path: "Port", Key: "DB_PORT", Source: envset.SourceDefault, Value: "5432"},
+		{Path: "User", Key: "DB_USER", Source: envset.SourcePreset, Value: "admin"},
+		{Path: "Password", Key: "DB_PASSWORD", Source: "map", Value: "******", Secret: true},
+		{Path: "Timeout", Key: "TIMEOUT", Source: envset.SourceUnset, Value: "0s"},
+		{Path: "Name", Key: "NAME", Source: envset.SourceUnset},
+		{Path: "Tags", Key: "TAGS", Source: envset.SourcePreset, Value: "a,b"},
+	}, dump.Fields)
+
+	assert.Equal(t, `DB_HOST      "db host"  map
+DB_PORT      5432       default
+DB_USER      admin      preset
+DB_PASSWORD  ******     map
+TIMEOUT      0s         unset
+NAME         ""         unset
+TAGS         a,b        preset
+`, dump.String())
+
+	data, err := json.Marshal(dump)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"DB_HOST": {"value": "db host", "source": "map"},
+		"DB_PORT": {"value": "5432", "source": "default"},
+		"DB_USER": {"value": "admin", "source": "preset"},
+		"DB_PASSWORD": {"value": "******", "source": "map", "secret": true},
+		"TIMEOUT": {"value": "0s", "source": "unset"},
+		"NAME": {"value": "", "source": "unset"},
+		"TAGS": {"value": "a,b", "source": "preset"}
+	}`, string(data))
+
+	var buf bytes.Buffer
+
+	slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})).Info("loaded", "config", dump)
+
+	assert.Equal(t, "level=INFO msg=loaded"+
+		` config.DB_HOST.value="db host" config.DB_HOST.source=map`+
+		" config.DB_PORT.value=5432 config.DB_PORT.source=default"+
+		" config.DB_USER.value=admin config.DB_USER.source=preset"+
+		" config.DB_PASSWORD.value=****** config.DB_PASSWORD.source=map"+
+		" config.TIMEOUT.value=0s config.TIMEOUT.source=unset"+
+		` config.NAME.value="" config.NAME.source=unset`+
+		" config.TAGS.value=a,b config.TAGS.source=preset\n", buf.String())
+}
+
+func TestLogger(t *testing.T) {
+	type T struct {
+		Host     string        `env:"DB_HOST"`
+		Port     int           `env:"DB_PORT" default:"5432"`
+		User     string        `env:"DB_USER"`
+		Password string        `env:"DB_PASSWORD" secret:"true"`
+		Timeout  time.Duration `env:"TIMEOUT,omitempty"`
+		Replicas int           `env:"REPLICAS" min:"1"`
+	}
+
+	var buf bytes.Buffer
+
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	v := T{User: "admin"}
+	err := envset.Set(&v,
+		envset.WithSource(envset.MapSource{"DB_HOST": "db", "DB_PASSWORD": "hunter2", "REPLICAS": "0"}),
+		envset.WithTypeParser(time.ParseDuration),
+		envset.WithLogger(logger),
+	)
+	require.Error(t, err)
+
+	assert.Equal(t, `level=DEBUG msg="value set" path=Host key=DB_HOST source=map value=db default=false
+level=DEBUG msg="default value used" path=Port key=DB_PORT source=default value=5432 default=true
+level=DEBUG msg="preset value kept" path=User key=DB_USER source=preset value=admin default=false
+level=DEBUG msg="value set" path=Password key=DB_PASSWORD source=map value=****** default=false
+level=DEBUG msg="optional value not set" path=Timeout key=TIMEOUT source=unset value="" default=false
+level=WARN msg="failed to set field" path=Replicas key=REPLICAS source=map default=false error="value 0 is less than the minimal value 1"
+`, buf.String())
+}
+
+func TestLoggerConditions(t *testing.T) {
+	type T struct {
+		TLSEnabled bool   `env:"TLS_ENABLED"`
+		TLSKeyFile string `env:"TLS_KEY_FILE" required_if:"TLSEnabled=true"`
+		Auth       struct {
+			Token    string `env:"TOKEN" oneof_group:"auth"`
+			Password string `env:"PASSWORD" oneof_group:"auth"`
+		}
+	}
+
+	var buf bytes.Buffer
+
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	var v T
+	err := envset.Set(&v,
+		envset.WithSource(envset.MapSource{"TLS_ENABLED": "yes", "TOKEN": "t", "PASSWORD": "p"}),
+		envset.WithAllErrors(),
+		envset.WithLogger(logger),
+	)
+	require.Error(t, err)
+
+	assert.Equal(t, `level=WARN msg="condition not met" path=Auth error="mutually exclusive values set in group auth: TOKEN, PASSWORD"
+level=WARN msg="condition not met" path=TLSKeyFile key=TLS_KEY_FILE error="value required if TLSEnabled=true, but not set: TLS_KEY_FILE"
+`, buf.String())
+}
+
+func TestDumpPresetWithDefault(t *testing.T) {
+	type T struct {
+		Port int `env:"PORT" default:"8080"`
+	}
+
+	var report envset.Report
+
+	src := envset.MapSource{}
+
+	v := T{Port: 9000}
+	require.NoError(t, envset.Set(&v, envset.WithSource(src), envset.WithReport(&report)))
+
+	// Sources are recorded by Set, changes of the source afterwards do not matter
+	src["PORT"] = "7000"
+
+	dump, err := envset.NewDump(&v, &report, envset.WithSource(src))
+	require.NoError(t, err)
+	assert.Equal(t, []envset.FieldReport{{Path: "Port", Key: "PORT", Source: envset.SourcePreset, Value: "9000"}}, dump.Fields)
+
+	dump, err = envset.NewDump(&T{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []envset.FieldReport{{Path: "Port", Key: "PORT", Source: envset.SourceUnset, Value: "0"}}, dump.Fields)
+}
+
+func TestDeprecatedKey(t *testing.T) {
+	type T struct {
+		URL  string `env:"DB_URL" deprecated:"DATABASE_URL, {{APP}}_DB"`
+		Port int    `env:"PORT,omitempty" deprecated:"HTTP_PORT"`
+	}
+
+	var buf bytes.Buffer
+
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	var v T
+	require.NoError(t, envset.Set(&v,
+		envset.WithSource(envset.MapSource{"APP_DB": "old", "HTTP_PORT": "80", "PORT": "8080"}),
+		envset.WithKeyVar("APP", "APP"),
+		envset.WithLogger(logger),
+	))
+	assert.Equal(t, T{URL: "old", Port: 8080}, v)
+	assert.Equal(t, `level=WARN msg="deprecated key used" path=URL key=DB_URL deprecated=APP_DB source=map
+`, buf.String())
+
+	v = T{}
+	require.ErrorIs(t, envset.Set(&v, envset.WithSource(envset.MapSource{})), envset.ErrUndefinedKeyVar)
+}
+
+func TestHandler(t *testing.T) {
+	type T struct {
+		Host     string `env:"DB_HOST"`
+		Port     int    `env:"DB_PORT" default:"5432"`
+		Password string `env:"DB_PASSWORD" secret:"true"`
+	}
+
+	src := envset.WithSource(envset.MapSource{"DB_HOST": "<db>", "DB_PASSWORD": "hunter2"})
+
+	var (
+		v      T
+		report envset.Report
+	)
+
+	require.NoError(t, envset.Set(&v, src, envset.WithReport(&report)))
+
+	handler := envset.Handler(&v, &report, src)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"DB_HOST": {"value": "<db>", "source": "map"},
+		"DB_PORT": {"value": "5432", "source": "default"},
+		"DB_PASSWORD": {"value": "******", "source": "map", "secret": true}
+	}`, rec.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/config", nil)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "<tr><td>DB_HOST</td><td>&lt;db&gt;</td><td>map</td><td>Host</td></tr>")
+	assert.Contains(t, rec.Body.String(), `<tr class="default"><td>DB_PORT</td><td>5432</td><td>default</td><td>Port</td></tr>`)
+	assert.NotContains(t, rec.Body.String(), "hunter2")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/config?format=html", nil))
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	envset.Publish("envset_test_config", &v, &report, src)
+	assert.JSONEq(t, `{
+		"DB_HOST": {"value": "<db>", "source": "map"},
+		"DB_PORT": {"value": "5432", "source": "default"},
+		"DB_PASSWORD": {"value": "******", "source": "map", "secret": true}
+	}`, expvar.Get("envset_test_config").String())
+}
+
+func TestHandlerError(t *testing.T) {
+	type T struct {
+		Keys []string `env:"{{SERVICE}}_KEYS" secret:"true"`
+	}
+
+	v := T{Keys: []string{"hunter2"}}
+
+	rec := httptest.NewRecorder()
+	envset.Handler(&v, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "configuration can not be rendered\n", rec.Body.String())
+
+	envset.Publish("envset_test_config_error", &v, nil)
+	assert.Equal(t, `"configuration can not be rendered"`, expvar.Get("envset_test_config_error").String())
+}
@@ -2,21 +2,105 @@ package envset
 
 import (
 	"errors"
+	"strings"
 )
 
 var (
 	ErrInvalidValue      = errors.New("invalid value")
 	ErrStructPtrExpected = errors.New("pointer to struct expected")
+	ErrKeysNotListable   = errors.New("source can not list keys")
+	ErrUndefinedKeyVar   = errors.New("undefined key variable")
+	ErrExpansionCycle    = errors.New("variable references itself")
+	ErrUnsetVariable     = errors.New("variable not set")
+	ErrIndexPattern      = errors.New("index pattern has no {index} placeholder")
 )
 
 type MissingValueError struct {
-	value string
+	value     string
+	condition string
 }
 
 func NewMissingValueError(value string) MissingValueError {
 	return MissingValueError{value: value}
 }
 
+// NewConditionalMissingValueError creates an error for a value
+// that is required only under the condition.
+func NewConditionalMissingValueError(value, condition string) MissingValueError {
+	return MissingValueError{value: value, condition: condition}
+}
+
 func (err MissingValueError) Error() string {
+	if err.condition != "" {
+		return "value required " + err.condition + ", but not set: " + err.value
+	}
+
 	return "value required, but not set: " + err.value
 }
+
+// GroupError reports values set for more than one alternative of a mutually exclusive group.
+type GroupError struct {
+	Group string
+	Keys  []string
+}
+
+func (err GroupError) Error() string {
+	return "mutually exclusive values set in group " + err.Group + ": " + strings.Join(err.Keys, ", ")
+}
+
+// FieldError describes a failure to populate a struct field.
+type FieldError struct {
+	// Path is the Go path of the field, e.g. Backends[1].Port
+	Path string
+	// Key is the key the value was looked up with
+	Key string
+	// Value is the raw value, empty if it was not set or is redacted
+	Value string
+	// Redacted is true when the value is hidden
+	Redacted bool
+	// Source is the name of the source of the value, or "default" for the default tag value
+	Source string
+	// Err is the cause
+	Err error
+}
+
+func (err *FieldError) Error() string {
+	if err.Key == "" {
+		return err.Path + ": " + err.Err.Error()
+	}
+
+	return err.Path + " (" + err.Key + "): " + err.Err.Error()
+}
+
+func (err *FieldError) Unwrap() error { return err.Err }
+
+// Errors is a list of errors returned by Set with WithAllErrors option.
+// It is compatible with errors.Is and errors.As.
+type Errors []error
+
+func (errs Errors) Error() string {
+	messages := make([]string, len(errs))
+	for i := range errs {
+		messages[i] = errs[i].Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+func (errs Errors) Unwrap() []error { return errs }
+
+// collect adds the error to the list, merging nested lists.
+// It returns true if processing should stop, that is, unless all errors are requested.
+func (p *parser) collect(errs *Errors, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if list, ok := err.(Errors); ok {
+		*errs = append(*errs, list...)
+	} else {
+		*errs = append(*errs, err)
+	}
+
+	return !p.allErrors
+}
@@ -0,0 +1,125 @@
+package envset
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const expandTag = "expand"
+
+// shouldExpand tells if the field value needs variables expansion,
+// either enabled with WithExpansion or by `expand:"true"` tag.
+func (p *parser) shouldExpand(tag reflect.StructTag) bool {
+	if val, ok := tag.Lookup(expandTag); ok {
+		return p.booleans[strings.ToLower(val)]
+	}
+
+	return p.expand
+}
+
+// expandValue substitutes ${VAR}, ${VAR:-fallback} and ${VAR:?error} references
+// with values from the source. A literal dollar sign is written as $$.
+// Values of referenced variables are expanded as well, the stack holds
+// variables being expanded to detect cycles.
+func (p *parser) expandValue(val string, stack []string) (string, error) {
+	if !strings.Contains(val, "$") {
+		return val, nil
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(val); i++ {
+		if val[i] != '$' || i+1 == len(val) {
+			b.WriteByte(val[i])
+			continue
+		}
+
+		switch val[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '{':
+			end := closingBrace(val, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("%w: unterminated reference in %q", ErrInvalidValue, val)
+			}
+
+			expanded, err := p.expandReference(val[i+2:end], stack)
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteString(expanded)
+			i = end
+		default:
+			b.WriteByte('$')
+		}
+	}
+
+	return b.String(), nil
+}
+
+// expandReference resolves the contents of ${...}.
+func (p *parser) expandReference(ref string, stack []string) (string, error) {
+	name, op, arg := ref, "", ""
+	if i := strings.Index(ref, ":"); i >= 0 && i+1 < len(ref) && (ref[i+1] == '-' || ref[i+1] == '?') {
+		name, op, arg = ref[:i], ref[i:i+2], ref[i+2:]
+	}
+
+	for _, s := range stack {
+		if s == name {
+			return "", fmt.Errorf("%w: %s", ErrExpansionCycle, strings.Join(append(stack, name), " -> "))
+		}
+	}
+
+	val, ok := p.source.LookupEnv(name)
+	if ok {
+		var err error
+		if val, err = p.expandValue(val, append(stack[:len(stack):len(stack)], name)); err != nil {
+			return "", err
+		}
+	}
+
+	if val != "" {
+		return val, nil
+	}
+
+	switch op {
+	case ":-":
+		return p.expandValue(arg, stack)
+	case ":?":
+		msg, err := p.expandValue(arg, stack)
+		if err != nil {
+			return "", err
+		}
+
+		if msg == "" {
+			msg = "not set"
+		}
+
+		return "", fmt.Errorf("%w: %s: %s", ErrUnsetVariable, name, msg)
+	default:
+		return "", nil
+	}
+}
+
+// closingBrace finds the brace closing the reference starting at from, accounting for nested references.
+func closingBrace(val string, from int) int {
+	depth := 0
+
+	for i := from; i < len(val); i++ {
+		switch {
+		case val[i] == '$' && i+1 < len(val) && val[i+1] == '{':
+			depth++
+			i++
+		case val[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+
+	return -1
+}
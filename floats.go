@@ -8,23 +8,6 @@ import (
 
 type float interface{ ~float32 | ~float64 }
 
-func setSliceOfFloats[F float](val []string, f reflect.Value) error {
-	var values []F
-
-	for _, v := range val {
-		i, err := parseFloat[F](v)
-		if err != nil {
-			return err
-		}
-
-		values = append(values, i)
-	}
-
-	f.Set(reflect.ValueOf(values))
-
-	return nil
-}
-
 func lookupFloatTag[N float](tagName string, tag reflect.StructTag) (*N, error) {
 	value, ok := tag.Lookup(tagName)
 	if !ok {
@@ -56,6 +39,10 @@ func (p *parser) setFloat32(f reflect.Value, tag reflect.StructTag, val string)
 		return err
 	}
 
+	if _, err := checkEnum(tag, val, true); err != nil {
+		return err
+	}
+
 	if minValue, err := lookupFloatTag[float32]("min", tag); err != nil {
 		return fmt.Errorf("parsing min value: %w", err)
 	} else if minValue != nil && i < *minValue {
@@ -64,8 +51,8 @@ func (p *parser) setFloat32(f reflect.Value, tag reflect.StructTag, val string)
 
 	if maxValue, err := lookupFloatTag[float32]("max", tag); err != nil {
 		return fmt.Errorf("parsing max value: %w", err)
-	} else if maxValue != nil && i < *maxValue {
-		return fmt.Errorf("value %v is greater than the minimal value %v", val, *maxValue)
+	} else if maxValue != nil && i > *maxValue {
+		return fmt.Errorf("value %v is greater than the maximal value %v", val, *maxValue)
 	}
 
 	f.Set(reflect.ValueOf(i))
@@ -79,6 +66,10 @@ func (p *parser) setFloat64(f reflect.Value, tag reflect.StructTag, val string)
 		return err
 	}
 
+	if _, err := checkEnum(tag, val, true); err != nil {
+		return err
+	}
+
 	if minValue, err := lookupFloatTag[float64]("min", tag); err != nil {
 		return fmt.Errorf("parsing min value: %w", err)
 	} else if minValue != nil && i < *minValue {
@@ -87,8 +78,8 @@ func (p *parser) setFloat64(f reflect.Value, tag reflect.StructTag, val string)
 
 	if maxValue, err := lookupFloatTag[float64]("max", tag); err != nil {
 		return fmt.Errorf("parsing max value: %w", err)
-	} else if maxValue != nil && i < *maxValue {
-		return fmt.Errorf("value %v is greater than the minimal value %v", val, *maxValue)
+	} else if maxValue != nil && i > *maxValue {
+		return fmt.Errorf("value %v is greater than the maximal value %v", val, *maxValue)
 	}
 
 	v := reflect.ValueOf(i)
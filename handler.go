@@ -0,0 +1,81 @@
+package envset
+
+import (
+	"encoding/json"
+	"expvar"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// Handler serves the configuration held by the struct, the way NewDump renders it:
+// secret values masked, with the source of every value as recorded in the report filled by Set.
+// It responds with HTML to browsers and with JSON otherwise, "format" query parameter set to "json"
+// or "html" overrides the choice. Current values are rendered on every request, with the options
+// passed to Set; if it fails, the response is a generic error, not revealing the cause:
+//
+//	http.Handle("/debug/config", envset.Handler(&cfg, &report))
+func Handler[T any](structPtr *T, report *Report, options ...Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dump, err := NewDump(structPtr, report, options...)
+		if err != nil {
+			// The error is not revealed, it may hold configuration details
+			http.Error(w, errDumpFailed, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "no-store")
+
+		format := r.URL.Query().Get("format")
+		if format == "" && strings.Contains(r.Header.Get("Accept"), "text/html") {
+			format = "html"
+		}
+
+		if format == "html" {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_ = dumpTemplate.Execute(w, dump)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(dump)
+	})
+}
+
+// errDumpFailed is reported instead of errors of NewDump.
+const errDumpFailed = "configuration can not be rendered"
+
+var dumpTemplate = template.Must(template.New("config").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>Configuration</title>
+<style>
+table { border-collapse: collapse; font-family: monospace; }
+th, td { border: 1px solid #ccc; padding: 2px 8px; text-align: left; }
+.default { color: #888; }
+</style>
+</head>
+<body>
+<table>
+<tr><th>Variable</th><th>Value</th><th>Source</th><th>Field</th></tr>
+{{range .Fields}}<tr{{if eq .Source "default"}} class="default"{{end}}><td>{{.Key}}</td><td>{{.Value}}</td><td>{{.Source}}</td><td>{{.Path}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// Publish publishes the configuration held by the struct as an expvar variable with the name,
+// rendered by NewDump with the report filled by Set on every read, or a generic error message.
+// Like expvar.Publish, it panics if the name is already in use.
+func Publish[T any](name string, structPtr *T, report *Report, options ...Option) {
+	expvar.Publish(name, expvar.Func(func() any {
+		dump, err := NewDump(structPtr, report, options...)
+		if err != nil {
+			return errDumpFailed
+		}
+
+		return dump
+	}))
+}
@@ -10,23 +10,6 @@ type integer interface {
 	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
 }
 
-func setSliceOfIntegers[N integer](val []string, f reflect.Value) error {
-	var values []N
-
-	for _, v := range val {
-		i, err := parseInteger[N](v)
-		if err != nil {
-			return err
-		}
-
-		values = append(values, i)
-	}
-
-	f.Set(reflect.ValueOf(values))
-
-	return nil
-}
-
 func lookupIntegerTag[N integer](tagName string, tag reflect.StructTag) (*N, error) {
 	value, ok := tag.Lookup(tagName)
 	if !ok {
@@ -58,6 +41,10 @@ func setInteger[N integer](f reflect.Value, tag reflect.StructTag, val string) e
 		return err
 	}
 
+	if _, err := checkEnum(tag, val, true); err != nil {
+		return err
+	}
+
 	if minValue, err := lookupIntegerTag[N]("min", tag); err != nil {
 		return fmt.Errorf("parsing min value: %w", err)
 	} else if minValue != nil && i < *minValue {
@@ -66,8 +53,8 @@ func setInteger[N integer](f reflect.Value, tag reflect.StructTag, val string) e
 
 	if maxValue, err := lookupIntegerTag[N]("max", tag); err != nil {
 		return fmt.Errorf("parsing max value: %w", err)
-	} else if maxValue != nil && i < *maxValue {
-		return fmt.Errorf("value %v is greater than the minimal value %v", val, *maxValue)
+	} else if maxValue != nil && i > *maxValue {
+		return fmt.Errorf("value %v is greater than the maximal value %v", val, *maxValue)
 	}
 
 	v := reflect.ValueOf(i)
@@ -0,0 +1,91 @@
+package envset
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const deprecatedTag = "deprecated"
+
+// expandKey substitutes {{NAME}} placeholders in the key with values set by WithKeyVar.
+func (p *parser) expandKey(key string) (string, error) {
+	if !strings.Contains(key, "{{") {
+		return key, nil
+	}
+
+	var b strings.Builder
+
+	for rest := key; rest != ""; {
+		before, after, ok := strings.Cut(rest, "{{")
+		b.WriteString(before)
+
+		if !ok {
+			break
+		}
+
+		name, tail, ok := strings.Cut(after, "}}")
+		if !ok {
+			return "", fmt.Errorf("unterminated key template in %s", key)
+		}
+
+		val, ok := p.keyVars[strings.TrimSpace(name)]
+		if !ok {
+			return "", fmt.Errorf("%w %s in key %s", ErrUndefinedKeyVar, strings.TrimSpace(name), key)
+		}
+
+		b.WriteString(val)
+		rest = tail
+	}
+
+	return b.String(), nil
+}
+
+// mapKey applies the key mapper set by WithKeyMapper.
+func (p *parser) mapKey(fieldPath []string, key string) string {
+	if p.keyMapper == nil {
+		return key
+	}
+
+	return p.keyMapper(fieldPath, key)
+}
+
+// fieldKey resolves the key of the field in the scope. Fields with conditional
+// requirements are optional, as they are checked after the struct is populated.
+func (p *parser) fieldKey(field reflect.StructField, s scope) (key string, exist, optional bool, err error) {
+	if key, exist, optional, err = p.tagKey(field.Tag); !exist || err != nil {
+		return
+	}
+
+	return p.mapKey(s.names(field.Name), s.prefix+key), exist, optional || isConditional(field.Tag), nil
+}
+
+// lookup looks the value of the field up by its key, falling back to the keys listed
+// in the `deprecated` tag, e.g. `env:"DB_URL" deprecated:"DATABASE_URL"`.
+// The use of a deprecated key is logged at warn level.
+func (p *parser) lookup(s scope, field reflect.StructField, key string) (string, bool, error) {
+	if val, ok := p.source.LookupEnv(key); ok {
+		return val, true, nil
+	}
+
+	deprecated, ok := field.Tag.Lookup(deprecatedTag)
+	if !ok {
+		return "", false, nil
+	}
+
+	for _, old := range strings.Split(deprecated, ",") {
+		old, err := p.expandKey(strings.TrimSpace(old))
+		if err != nil {
+			return "", false, err
+		}
+
+		old = p.mapKey(s.names(field.Name), s.prefix+old)
+
+		if val, ok := p.source.LookupEnv(old); ok {
+			p.logDeprecated(s, field, key, old)
+			return val, true, nil
+		}
+	}
+
+	return "", false, nil
+}
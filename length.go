@@ -0,0 +1,37 @@
+package envset
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// checkLength validates length of a string (in runes) or number of slice elements
+// against minlen, maxlen and len tags.
+func checkLength(tag reflect.StructTag, length int) error {
+	for _, limit := range []struct {
+		tag     string
+		invalid func(limit int) bool
+		message string
+	}{
+		{tag: "len", invalid: func(limit int) bool { return length != limit }, message: "is not equal to the length"},
+		{tag: "minlen", invalid: func(limit int) bool { return length < limit }, message: "is less than the minimal length"},
+		{tag: "maxlen", invalid: func(limit int) bool { return length > limit }, message: "is greater than the maximal length"},
+	} {
+		value, ok := tag.Lookup(limit.tag)
+		if !ok {
+			continue
+		}
+
+		l, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("parsing %s value: %w", limit.tag, err)
+		}
+
+		if limit.invalid(l) {
+			return fmt.Errorf("%w: length %d %s %d", ErrInvalidValue, length, limit.message, l)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package envset
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+// logField logs the origin of the field value at debug level.
+func (p *parser) logField(field FieldReport) {
+	if p.logger == nil {
+		return
+	}
+
+	message := "value set"
+
+	switch field.Source {
+	case SourceDefault:
+		message = "default value used"
+	case SourceUnset:
+		message = "optional value not set"
+	case SourcePreset:
+		message = "preset value kept"
+	}
+
+	p.logger.LogAttrs(context.Background(), slog.LevelDebug, message,
+		slog.String("path", field.Path),
+		slog.String("key", field.Key),
+		slog.String("source", field.Source),
+		slog.String("value", field.Value),
+		slog.Bool("default", field.Source == SourceDefault),
+	)
+}
+
+// logDeprecated logs the use of a deprecated key in place of the key of the field at warn level.
+func (p *parser) logDeprecated(s scope, field reflect.StructField, key, deprecated string) {
+	if p.logger == nil {
+		return
+	}
+
+	p.logger.LogAttrs(context.Background(), slog.LevelWarn, "deprecated key used",
+		slog.String("path", s.fieldPath(field.Name)),
+		slog.String("key", key),
+		slog.String("deprecated", deprecated),
+		slog.String("source", p.sourceName()),
+	)
+}
+
+// logError logs the failure of the field at warn level. Errors of nested fields
+// are logged where they occur, and values of secret fields are already redacted.
+func (p *parser) logError(s scope, field reflect.StructField, err error) {
+	var fieldErr *FieldError
+	if p.logger == nil || !errors.As(err, &fieldErr) || fieldErr.Path != s.fieldPath(field.Name) {
+		return
+	}
+
+	p.logger.LogAttrs(context.Background(), slog.LevelWarn, "failed to set field",
+		slog.String("path", fieldErr.Path),
+		slog.String("key", fieldErr.Key),
+		slog.String("source", fieldErr.Source),
+		slog.Bool("default", fieldErr.Source == SourceDefault),
+		slog.String("error", fieldErr.Err.Error()),
+	)
+}
+
+// logConditions logs the failures of conditional requirements and groups of the struct at warn level.
+// Group errors are not bound to a field, they are logged with the path of the struct.
+func (p *parser) logConditions(s scope, err error) {
+	if p.logger == nil || err == nil {
+		return
+	}
+
+	errs, ok := err.(Errors)
+	if !ok {
+		errs = Errors{err}
+	}
+
+	for _, err := range errs {
+		attrs := []slog.Attr{slog.String("path", strings.Join(s.path, ".")), slog.String("error", err.Error())}
+
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) {
+			attrs = []slog.Attr{
+				slog.String("path", fieldErr.Path),
+				slog.String("key", fieldErr.Key),
+				slog.String("error", fieldErr.Err.Error()),
+			}
+		}
+
+		p.logger.LogAttrs(context.Background(), slog.LevelWarn, "condition not met", attrs...)
+	}
+}
@@ -0,0 +1,98 @@
+package envset
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Markdown writes documentation of the environment variables of the struct as Markdown tables,
+// one per nested struct, with keys, types, defaults, constraints, descriptions from the `desc` tag
+// and examples from the `example` tag.
+func Markdown[T any](w io.Writer, structPtr *T, options ...Option) error {
+	fields, err := describeStruct(reflect.TypeOf(structPtr).Elem(), docPlaceholders, options)
+	if err != nil {
+		return err
+	}
+
+	var (
+		groups  []string
+		byGroup = make(map[string][]fieldInfo)
+	)
+
+	for _, field := range fields {
+		if _, ok := byGroup[field.group]; !ok {
+			groups = append(groups, field.group)
+		}
+
+		byGroup[field.group] = append(byGroup[field.group], field)
+	}
+
+	for i, group := range groups {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+
+		if group != "" {
+			if _, err := fmt.Fprintf(w, "## %s\n\n", markdownEscape(group)); err != nil {
+				return err
+			}
+		}
+
+		if err := markdownTable(w, byGroup[group]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func markdownTable(w io.Writer, fields []fieldInfo) error {
+	if _, err := io.WriteString(w, "| Variable | Type | Required | Default | Constraints | Description | Example |\n"+
+		"|----------|------|----------|---------|-------------|-------------|---------|\n"); err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		required := "no"
+		if field.required() {
+			required = "yes"
+		}
+
+		constraints := field.constraints()
+		for i := range constraints {
+			constraints[i] = markdownCode(constraints[i])
+		}
+
+		if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s |\n",
+			markdownCode(field.key),
+			markdownCode(typeName(field.typ)),
+			required,
+			markdownCode(field.defaultValue()),
+			strings.Join(constraints, ", "),
+			markdownEscape(field.tag.Get(descTag)),
+			markdownCode(field.tag.Get(exampleTag)),
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// markdownCode formats the text as inline code, empty text stays empty.
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	return "`" + strings.ReplaceAll(s, "|", `\|`) + "`"
+}
+
+// markdownEscape escapes characters having special meaning in table cells.
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "<", "&lt;", ">", "&gt;", "\n", " ").Replace(s)
+}
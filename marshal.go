@@ -0,0 +1,292 @@
+package envset
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// keyValue is a key with the value written by Marshal.
+type keyValue struct {
+	key, value string
+	// path is the Go path of the field
+	path  string
+	scope scope
+	field reflect.StructField
+	// f is the field value
+	f reflect.Value
+	// zero tells if the field has zero value
+	zero bool
+	// omit is true for nil pointers and empty slices, which can not be written
+	omit bool
+}
+
+// Marshal is the inverse of Set: it returns values of the struct fields as KEY=VALUE pairs
+// in os.Environ format, e.g. for the environment of a subprocess, so that Set with the same
+// options populates an equal struct. Keys, slice separators and custom types are handled
+// the same way as by Set, values of custom types are written by formatters added with
+// WithTypeFormatter, or with fmt.Sprint. Nil pointers, empty slices and empty maps are left out.
+// Secret values are written as is.
+func Marshal[T any](structPtr *T, options ...Option) ([]string, error) {
+	pairs, err := marshal(structPtr, options)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make([]string, len(pairs))
+	for i := range pairs {
+		env[i] = pairs[i].key + "=" + pairs[i].value
+	}
+
+	return env, nil
+}
+
+// MarshalMap is Marshal returning a map of keys to values, e.g. to be used with MapSource.
+func MarshalMap[T any](structPtr *T, options ...Option) (map[string]string, error) {
+	pairs, err := marshal(structPtr, options)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		m[pair.key] = pair.value
+	}
+
+	return m, nil
+}
+
+func marshal[T any](structPtr *T, options []Option) ([]keyValue, error) {
+	if reflect.TypeOf(structPtr).Elem().Kind() != reflect.Struct {
+		panic(ErrStructPtrExpected)
+	}
+
+	p := buildParser(options)
+
+	pairs, err := p.marshalPairs(reflect.ValueOf(structPtr).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	written := pairs[:0]
+
+	for _, pair := range pairs {
+		if pair.omit {
+			continue
+		}
+
+		if err := p.checkSeparator(pair.f); err != nil {
+			return nil, p.valueError(pair.scope, pair.field, pair.key, "", "", err)
+		}
+
+		// A literal dollar sign is escaped in values to be expanded
+		if p.shouldExpand(pair.field.Tag) {
+			pair.value = strings.ReplaceAll(pair.value, "$", "$$")
+		}
+
+		written = append(written, pair)
+	}
+
+	return written, nil
+}
+
+// marshalPairs lists the keys and values of the struct fields.
+func (p *parser) marshalPairs(v reflect.Value) ([]keyValue, error) {
+	var pairs []keyValue
+
+	if err := p.marshalStruct(v, scope{}, &pairs); err != nil {
+		return nil, err
+	}
+
+	return pairs, nil
+}
+
+// marshalStruct appends values of the struct fields to the pairs.
+func (p *parser) marshalStruct(v reflect.Value, s scope, pairs *[]keyValue) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if err := p.marshalField(v.Field(i), field, s, pairs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *parser) marshalField(f reflect.Value, field reflect.StructField, s scope, pairs *[]keyValue) error {
+	if !p.isCustom(f.Type()) {
+		switch {
+		case structType(f.Type()) != nil:
+			// Set allocates nil pointers to structs, so they are written as zero values
+			if f.Kind() == reflect.Pointer && f.IsNil() {
+				f = reflect.New(f.Type().Elem())
+			}
+
+			return p.marshalStruct(reflect.Indirect(f), s.child(field.Name), pairs)
+		case p.isStructSlice(f.Type()), p.isStructMap(f.Type()):
+			return p.marshalCollection(f, field, s, pairs)
+		}
+	}
+
+	key, ok, _, err := p.fieldKey(field, s)
+	if err != nil {
+		return s.fieldError(field.Name, "", err)
+	}
+
+	if !ok {
+		return nil
+	}
+
+	pair := keyValue{key: key, path: s.fieldPath(field.Name), scope: s, field: field, f: f, zero: f.IsZero()}
+
+	if f.Kind() == reflect.Pointer && f.IsNil() || f.Kind() == reflect.Slice && f.Len() == 0 && !p.isCustom(f.Type()) {
+		pair.omit = true
+		*pairs = append(*pairs, pair)
+
+		return nil
+	}
+
+	if pair.value, err = p.formatValue(f); err != nil {
+		return p.valueError(s, field, key, "", "", err)
+	}
+
+	*pairs = append(*pairs, pair)
+
+	return nil
+}
+
+// marshalCollection writes elements of a slice or map of structs with indexed keys.
+func (p *parser) marshalCollection(f reflect.Value, field reflect.StructField, s scope, pairs *[]keyValue) error {
+	key, ok, _, err := p.tagKey(field.Tag)
+	if err != nil {
+		return s.fieldError(field.Name, "", err)
+	}
+
+	if !ok {
+		return nil
+	}
+
+	key = s.prefix + key
+
+	var (
+		indexes []string
+		elems   []reflect.Value
+	)
+
+	if f.Kind() == reflect.Slice {
+		for i := 0; i < f.Len(); i++ {
+			indexes, elems = append(indexes, strconv.Itoa(i)), append(elems, f.Index(i))
+		}
+	} else {
+		names := f.MapKeys()
+		sort.Slice(names, func(i, j int) bool { return names[i].String() < names[j].String() })
+
+		for _, name := range names {
+			indexes, elems = append(indexes, name.String()), append(elems, f.MapIndex(name))
+		}
+	}
+
+	for i, elem := range elems {
+		if elem.Kind() == reflect.Pointer && elem.IsNil() {
+			elem = reflect.New(elem.Type().Elem())
+		}
+
+		es := s.element(field.Name, indexes[i], p.indexPrefix(key, indexes[i]))
+		if err := p.marshalStruct(reflect.Indirect(elem), es, pairs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isCustom tells if values of the type are handled by a custom parser or formatter.
+func (p *parser) isCustom(t reflect.Type) bool {
+	_, parser := p.customTypes[t]
+	_, formatter := p.formatters[t]
+
+	return parser || formatter
+}
+
+// formatValue formats the value the way Set parses it.
+func (p *parser) formatValue(f reflect.Value) (string, error) {
+	if format, ok := p.formatters[f.Type()]; ok {
+		return format(f), nil
+	}
+
+	if _, ok := p.customTypes[f.Type()]; ok {
+		return fmt.Sprint(f.Interface()), nil
+	}
+
+	if f.Kind() == reflect.Pointer {
+		if f.IsNil() {
+			return "", errors.New("nil element")
+		}
+
+		f = f.Elem()
+	}
+
+	switch f.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(f.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(f.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(f.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(f.Float(), 'g', -1, f.Type().Bits()), nil
+	case reflect.String:
+		return f.String(), nil
+	case reflect.Slice:
+		return p.formatSlice(f)
+	default:
+		return "", fmt.Errorf("unsupported type %s of %s", f.Kind(), f.Type())
+	}
+}
+
+// formatSlice joins the formatted elements with the slice separator.
+func (p *parser) formatSlice(f reflect.Value) (string, error) {
+	if !p.isCustom(f.Type().Elem()) && !isSupportedElem(f.Type().Elem()) {
+		return "", errors.New("unsupported slice elements type: " + f.Type().Elem().Kind().String())
+	}
+
+	elems := make([]string, f.Len())
+
+	for i := range elems {
+		val, err := p.formatValue(f.Index(i))
+		if err != nil {
+			return "", fmt.Errorf("element %d: %w", i, err)
+		}
+
+		elems[i] = val
+	}
+
+	return strings.Join(elems, p.sliceSeparator), nil
+}
+
+// checkSeparator reports slice elements containing the slice separator,
+// which Set would read back as separate elements.
+func (p *parser) checkSeparator(f reflect.Value) error {
+	if f.Kind() == reflect.Pointer && !p.isCustom(f.Type()) {
+		f = f.Elem()
+	}
+
+	if f.Kind() != reflect.Slice || p.isCustom(f.Type()) || p.sliceSeparator == "" {
+		return nil
+	}
+
+	for i := 0; i < f.Len(); i++ {
+		if val, err := p.formatValue(f.Index(i)); err == nil && strings.Contains(val, p.sliceSeparator) {
+			return fmt.Errorf("element %d contains the slice separator %s", i, p.sliceSeparator)
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,9 @@
 package envset
 
-import "reflect"
+import (
+	"log/slog"
+	"reflect"
+)
 
 type Option func(*parser)
 
@@ -37,7 +40,95 @@ func WithTypeParser[T any](fn func(val string) (T, error)) Option {
 	}
 }
 
+// WithTypeFormatter adds a formatter for a custom type, used by Marshal to write values
+// read back by the parser added with WithTypeParser.
+func WithTypeFormatter[T any](fn func(val T) string) Option {
+	return func(p *parser) {
+		var t T
+		p.formatters[reflect.TypeOf(t)] = func(v reflect.Value) string {
+			return fn(v.Interface().(T))
+		}
+	}
+}
+
 // WithCustomBools adds a pair of values to be interpreted as true/false
 func WithCustomBools(asTrue, asFalse string) Option {
 	return func(p *parser) { p.booleans[asTrue], p.booleans[asFalse] = true, false }
 }
+
+// WithIndexPattern sets the pattern used to build key prefixes of slice of struct elements.
+// The pattern may use {key} and must use {index} placeholder, the default is "{key}_{index}_".
+func WithIndexPattern(pattern string) Option {
+	return func(p *parser) {
+		p.indexPattern = pattern
+	}
+}
+
+// WithSource sets the source of values, the process environment is used by default.
+func WithSource(src Source) Option {
+	return func(p *parser) {
+		p.source = src
+	}
+}
+
+// WithKeyVar defines a variable to be substituted in keys, e.g. `env:"{{SERVICE}}_PORT"`.
+func WithKeyVar(name, value string) Option {
+	return func(p *parser) {
+		p.keyVars[name] = value
+	}
+}
+
+// WithKeyMapper sets a function to transform every key before lookup,
+// e.g. to add a platform specific prefix. The function receives the Go path of the field
+// and the key resolved from the tags.
+func WithKeyMapper(fn func(fieldPath []string, tagKey string) string) Option {
+	return func(p *parser) {
+		p.keyMapper = fn
+	}
+}
+
+// WithExpansion enables ${VAR} style expansion in values and defaults of all fields.
+// Particular fields can opt in or out with `expand:"true"` or `expand:"false"` tags.
+func WithExpansion() Option {
+	return func(p *parser) {
+		p.expand = true
+	}
+}
+
+// WithAllErrors makes Set continue after failing fields and return
+// all the errors as Errors, instead of stopping at the first one.
+func WithAllErrors() Option {
+	return func(p *parser) {
+		p.allErrors = true
+	}
+}
+
+// WithSecretKeys marks values of the keys as secret, same as `secret:"true"` tag,
+// so they never appear in errors and reports.
+func WithSecretKeys(keys ...string) Option {
+	return func(p *parser) {
+		for _, key := range keys {
+			p.secretKeys[key] = true
+		}
+	}
+}
+
+// WithLogger makes Set log every field with the source of its value at debug level,
+// and fields failing to get a value at warn level. Secret values are masked.
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *parser) {
+		p.logger = logger
+	}
+}
+
+// WithReport makes Set fill the report with the origin of every field value.
+// A nil report is ignored, like a nil logger of WithLogger.
+func WithReport(report *Report) Option {
+	return func(p *parser) {
+		if report != nil {
+			report.Fields = nil
+		}
+
+		p.report = report
+	}
+}
@@ -0,0 +1,82 @@
+package envset
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Report lists the fields visited by Set and where their values came from.
+type Report struct {
+	Fields []FieldReport
+}
+
+// FieldReport describes the origin of a field value.
+type FieldReport struct {
+	// Path is the Go path of the field, e.g. Backends[1].Port
+	Path string
+	// Key is the key the value was looked up with
+	Key string
+	// Source is the name of the source of the value, or one of SourceDefault, SourcePreset, SourceUnset
+	Source string
+	// Value is the raw value, masked for secret fields
+	Value string
+	// Secret is true for secret fields
+	Secret bool
+}
+
+// Field returns the report of the field with the Go path, if there is one.
+func (r *Report) Field(path string) (FieldReport, bool) {
+	for _, field := range r.Fields {
+		if field.Path == path {
+			return field, true
+		}
+	}
+
+	return FieldReport{}, false
+}
+
+// record adds the field to the report requested with WithReport, and logs it with the logger set by WithLogger.
+func (p *parser) record(s scope, field reflect.StructField, key, source, val string) {
+	if p.report == nil && p.logger == nil {
+		return
+	}
+
+	secret := p.isSecret(field.Tag, key)
+	if secret && val != "" {
+		val = maskedValue
+	}
+
+	fieldReport := FieldReport{
+		Path:   s.fieldPath(field.Name),
+		Key:    key,
+		Source: source,
+		Value:  val,
+		Secret: secret,
+	}
+
+	p.logField(fieldReport)
+
+	if p.report != nil {
+		p.report.Fields = append(p.report.Fields, fieldReport)
+	}
+}
+
+// recordPreset adds the tagged field that had value before Set was called to the report.
+func (p *parser) recordPreset(s scope, field reflect.StructField, f reflect.Value) {
+	if p.report == nil && p.logger == nil {
+		return
+	}
+
+	key, ok, _, err := p.tagKey(field.Tag)
+	if !ok || err != nil {
+		return
+	}
+
+	if !p.isStructSlice(field.Type) && !p.isStructMap(field.Type) {
+		key = p.mapKey(s.names(field.Name), s.prefix+key)
+	} else {
+		key = s.prefix + key
+	}
+
+	p.record(s, field, key, SourcePreset, fmt.Sprint(reflect.Indirect(f).Interface()))
+}
@@ -0,0 +1,194 @@
+package envset
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaPlaceholders are replaced with patterns in property names of collection elements.
+var schemaPlaceholders = placeholders{index: "\x00index\x00", name: "\x00name\x00"}
+
+// jsonSchema is a subset of JSON Schema used to describe configuration.
+type jsonSchema struct {
+	Schema            string                 `json:"$schema,omitempty"`
+	Type              string                 `json:"type,omitempty"`
+	Description       string                 `json:"description,omitempty"`
+	Properties        map[string]*jsonSchema `json:"properties,omitempty"`
+	PatternProperties map[string]*jsonSchema `json:"patternProperties,omitempty"`
+	Required          []string               `json:"required,omitempty"`
+	Items             *jsonSchema            `json:"items,omitempty"`
+	Enum              []any                  `json:"enum,omitempty"`
+	Pattern           string                 `json:"pattern,omitempty"`
+	Minimum           json.Number            `json:"minimum,omitempty"`
+	Maximum           json.Number            `json:"maximum,omitempty"`
+	MinLength         *int                   `json:"minLength,omitempty"`
+	MaxLength         *int                   `json:"maxLength,omitempty"`
+	MinItems          *int                   `json:"minItems,omitempty"`
+	MaxItems          *int                   `json:"maxItems,omitempty"`
+	UniqueItems       bool                   `json:"uniqueItems,omitempty"`
+	Default           any                    `json:"default,omitempty"`
+	Examples          []any                  `json:"examples,omitempty"`
+	WriteOnly         bool                   `json:"writeOnly,omitempty"`
+}
+
+// JSONSchema exports the configuration struct as JSON Schema (draft 2020-12):
+// an object keyed by variable names with types, defaults and constraints taken from the tags.
+// Keys of collection elements are described with patternProperties.
+func JSONSchema[T any](structPtr *T, options ...Option) ([]byte, error) {
+	fields, err := describeStruct(reflect.TypeOf(structPtr).Elem(), schemaPlaceholders, options)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &jsonSchema{
+		Schema:     jsonSchemaDraft,
+		Type:       "object",
+		Properties: make(map[string]*jsonSchema),
+	}
+
+	for _, field := range fields {
+		property := fieldSchema(field)
+
+		if !strings.Contains(field.key, "\x00") {
+			root.Properties[field.key] = property
+
+			if field.required() {
+				root.Required = append(root.Required, field.key)
+			}
+
+			continue
+		}
+
+		if root.PatternProperties == nil {
+			root.PatternProperties = make(map[string]*jsonSchema)
+		}
+
+		root.PatternProperties[keyPattern(field.key)] = property
+	}
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// keyPattern turns the key with placeholders into a regular expression.
+func keyPattern(key string) string {
+	return "^" + strings.NewReplacer(
+		schemaPlaceholders.index, "[0-9]+",
+		schemaPlaceholders.name, ".+",
+	).Replace(regexp.QuoteMeta(key)) + "$"
+}
+
+// fieldSchema describes the field according to its type and tags.
+func fieldSchema(field fieldInfo) *jsonSchema {
+	t := field.typ
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	schema := &jsonSchema{Description: field.tag.Get(descTag)}
+	value := schema
+
+	if t.Kind() == reflect.Slice {
+		schema.Type = "array"
+		schema.MinItems, schema.MaxItems = lengthLimits(field.tag)
+		schema.UniqueItems, _ = strconv.ParseBool(field.tag.Get("unique"))
+		schema.Items = &jsonSchema{}
+		value = schema.Items
+
+		if t = t.Elem(); t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+	}
+
+	value.Type = "string"
+	if !field.custom {
+		value.Type = jsonType(t)
+	}
+
+	if value.Type == "string" && value == schema {
+		value.MinLength, value.MaxLength = lengthLimits(field.tag)
+	}
+
+	value.Pattern = field.tag.Get("pattern")
+	value.Minimum = json.Number(field.tag.Get("min"))
+	value.Maximum = json.Number(field.tag.Get("max"))
+
+	if enum, ok := field.tag.Lookup(enumTag); ok {
+		for _, val := range strings.Split(enum, ",") {
+			value.Enum = append(value.Enum, jsonValue(value.Type, strings.TrimSpace(val)))
+		}
+	}
+
+	if field.hasDefault && !field.secret {
+		if schema.Type == "array" {
+			var items []any
+			for _, val := range strings.Split(field.def, field.separator) {
+				items = append(items, jsonValue(value.Type, val))
+			}
+
+			schema.Default = items
+		} else {
+			schema.Default = jsonValue(value.Type, field.def)
+		}
+	}
+
+	if example, ok := field.tag.Lookup(exampleTag); ok && !field.secret {
+		schema.Examples = []any{example}
+	}
+
+	schema.WriteOnly = field.secret
+
+	return schema
+}
+
+// jsonType maps Go kinds to JSON types.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// jsonValue converts the tag value to the JSON type, keeping it as is if it does not parse.
+func jsonValue(typ, val string) any {
+	switch typ {
+	case "boolean":
+		if b, ok := defaultBooleans[strings.ToLower(val)]; ok {
+			return b
+		}
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(val, 64); err == nil {
+			return json.Number(val)
+		}
+	}
+
+	return val
+}
+
+// lengthLimits returns the limits set by len, minlen and maxlen tags.
+func lengthLimits(tag reflect.StructTag) (minLength, maxLength *int) {
+	if l, err := strconv.Atoi(tag.Get("len")); err == nil {
+		return &l, &l
+	}
+
+	if l, err := strconv.Atoi(tag.Get("minlen")); err == nil {
+		minLength = &l
+	}
+
+	if l, err := strconv.Atoi(tag.Get("maxlen")); err == nil {
+		maxLength = &l
+	}
+
+	return minLength, maxLength
+}
@@ -0,0 +1,70 @@
+package envset
+
+import (
+	"fmt"
+	"strings"
+)
+
+// scope describes the position of a struct being populated within the
+// top-level struct passed to Set.
+type scope struct {
+	// prefix is prepended to every key of the struct, used by indexed elements
+	prefix string
+	// path is the Go field path leading to the struct
+	path []string
+}
+
+// child returns the scope of a nested struct field.
+func (s scope) child(name string) scope {
+	return scope{
+		prefix: s.prefix,
+		path:   append(s.path[:len(s.path):len(s.path)], name),
+	}
+}
+
+// element returns the scope of a collection element.
+func (s scope) element(name, index, prefix string) scope {
+	return scope{
+		prefix: prefix,
+		path:   append(s.path[:len(s.path):len(s.path)], name+"["+index+"]"),
+	}
+}
+
+// names returns the Go path of the named field.
+func (s scope) names(name string) []string {
+	return append(s.path[:len(s.path):len(s.path)], name)
+}
+
+// fieldPath returns the dotted Go path of the named field, e.g. Backends[1].Port.
+func (s scope) fieldPath(name string) string {
+	return strings.Join(s.names(name), ".")
+}
+
+// fieldError describes the failure of the named field, nil if there is no error.
+func (s scope) fieldError(name, key string, err error) error {
+	return s.valueError(name, key, "", "", err)
+}
+
+// valueError describes the failure of the named field to accept the value from the source.
+func (s scope) valueError(name, key, val, source string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &FieldError{
+		Path:   s.fieldPath(name),
+		Key:    key,
+		Value:  val,
+		Source: source,
+		Err:    err,
+	}
+}
+
+// wrapPath annotates errors of nested structs with the path of the struct.
+func (s scope) wrapPath(err error) error {
+	if err == nil || len(s.path) == 0 {
+		return err
+	}
+
+	return fmt.Errorf("%s: %w", strings.Join(s.path, "."), err)
+}
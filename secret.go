@@ -0,0 +1,96 @@
+package envset
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+const (
+	secretTag = "secret"
+	// maskedValue replaces values of secret fields
+	maskedValue = "******"
+)
+
+// isSecret tells if the field value must never be revealed,
+// either tagged with `secret:"true"` or having a key set by WithSecretKeys.
+func (p *parser) isSecret(tag reflect.StructTag, key string) bool {
+	if secret, _ := strconv.ParseBool(tag.Get(secretTag)); secret {
+		return true
+	}
+
+	return p.secretKeys[key]
+}
+
+// valueError describes the failure of the field to accept the value,
+// redacting the value of secret fields.
+func (p *parser) valueError(s scope, field reflect.StructField, key, val, source string, err error) error {
+	if err == nil || !p.isSecret(field.Tag, key) {
+		return s.valueError(field.Name, key, val, source, err)
+	}
+
+	return &FieldError{
+		Path:     s.fieldPath(field.Name),
+		Key:      key,
+		Redacted: true,
+		Source:   source,
+		Err:      Redact(err),
+	}
+}
+
+// Redact hides the error, as it may quote the value of a secret field in any form, in the message
+// or in the fields of a wrapped error. The error is not kept: only missing value errors, which name
+// just the key, are still wrapped, and errors.Is matches the sentinel errors the original error matched,
+// such as ErrInvalidValue or strconv.ErrSyntax. It is used by generated loaders.
+func Redact(err error) error {
+	var redacted redactedError
+
+	var missing MissingValueError
+	if errors.As(err, &missing) {
+		redacted.missing = missing
+	}
+
+	for _, sentinel := range redactedSentinels {
+		if errors.Is(err, sentinel) {
+			redacted.sentinels = append(redacted.sentinels, sentinel)
+		}
+	}
+
+	return redacted
+}
+
+// redactedSentinels are the errors carrying no values, which redacted errors keep matching.
+var redactedSentinels = []error{
+	ErrInvalidValue,
+	ErrUndefinedKeyVar,
+	ErrExpansionCycle,
+	ErrUnsetVariable,
+	strconv.ErrSyntax,
+	strconv.ErrRange,
+}
+
+// redactedError replaces the error with a fixed message.
+type redactedError struct {
+	missing   error
+	sentinels []error
+}
+
+func (err redactedError) Error() string {
+	if err.missing != nil {
+		return err.missing.Error()
+	}
+
+	return ErrInvalidValue.Error() + " (redacted)"
+}
+
+func (err redactedError) Is(target error) bool {
+	for _, sentinel := range err.sentinels {
+		if target == sentinel {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (err redactedError) Unwrap() error { return err.missing }
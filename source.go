@@ -0,0 +1,84 @@
+package envset
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Source provides values for keys, os.LookupEnv style.
+type Source interface {
+	LookupEnv(key string) (string, bool)
+}
+
+// KeyLister is implemented by sources that can enumerate their keys.
+// Maps of structs can only be populated from such sources.
+type KeyLister interface {
+	Keys() []string
+}
+
+// NamedSource is implemented by sources that have a name to be reported in errors.
+type NamedSource interface {
+	SourceName() string
+}
+
+// Pseudo source names reported for values not coming from a source.
+const (
+	// SourceDefault is reported for values taken from the default tag
+	SourceDefault = "default"
+	// SourcePreset is reported for fields having value before Set was called
+	SourcePreset = "preset"
+	// SourceUnset is reported for optional fields left without value
+	SourceUnset = "unset"
+)
+
+// sourceName returns the name of the source for error reports.
+func (p *parser) sourceName() string {
+	if named, ok := p.source.(NamedSource); ok {
+		return named.SourceName()
+	}
+
+	return fmt.Sprintf("%T", p.source)
+}
+
+// Environment returns the Source reading the process environment, the default one.
+func Environment() Source { return environment{} }
+
+// environment is the default source reading the process environment.
+type environment struct{}
+
+func (environment) SourceName() string { return "environment" }
+
+func (environment) LookupEnv(key string) (string, bool) { return os.LookupEnv(key) }
+
+func (environment) Keys() []string {
+	env := os.Environ()
+	keys := make([]string, 0, len(env))
+
+	for _, kv := range env {
+		if key, _, ok := strings.Cut(kv, "="); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// MapSource is a Source backed by a map.
+type MapSource map[string]string
+
+func (MapSource) SourceName() string { return "map" }
+
+func (m MapSource) LookupEnv(key string) (string, bool) {
+	val, ok := m[key]
+	return val, ok
+}
+
+func (m MapSource) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
@@ -13,6 +13,7 @@ func TestTags(t *testing.T) {
 		key      string
 		exist    bool
 		optional bool
+		err      error
 	}{
 		{
 			tag:      "",
@@ -38,20 +39,47 @@ func TestTags(t *testing.T) {
 			exist:    true,
 			optional: true,
 		},
+		{
+			tag:      `env:"{{SERVICE}}_PORT,omitempty"`,
+			key:      "API_PORT",
+			exist:    true,
+			optional: true,
+		},
+		{
+			tag:      `env:"{{ SERVICE }}_{{SERVICE}}"`,
+			key:      "API_API",
+			exist:    true,
+			optional: false,
+		},
+		{
+			tag:   `env:"{{UNDEFINED}}_PORT"`,
+			key:   "",
+			exist: true,
+			err:   ErrUndefinedKeyVar,
+		},
 	}
 
 	t.Setenv("IS_SET", "is_set")
 
-	p := buildParser([]Option{})
+	p := buildParser([]Option{WithKeyVar("SERVICE", "API")})
 
 	for _, tt := range testCases {
 		tt := tt
 
 		t.Run(string(tt.tag), func(t *testing.T) {
-			key, exists, optional := p.tagKey(tt.tag)
+			key, exists, optional, err := p.tagKey(tt.tag)
+			assert.ErrorIs(t, err, tt.err)
 			assert.Equal(t, tt.exist, exists)
 			assert.Equal(t, tt.key, key)
 			assert.Equal(t, tt.optional, optional)
 		})
 	}
 }
+
+func TestWithoutTags(t *testing.T) {
+	tag := reflect.StructTag(`env:"A" minlen:"1" pattern:"^\"[a-z]\"$" max:"5"`)
+
+	assert.Equal(t, reflect.StructTag(`env:"A" max:"5"`), withoutTags(tag, "minlen", "pattern"))
+	assert.Equal(t, `^"[a-z]"$`, withoutTags(tag, "env").Get("pattern"))
+	assert.Equal(t, reflect.StructTag(""), withoutTags("", "env"))
+}
@@ -0,0 +1,60 @@
+package envset
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// withoutTags returns the struct tag with the named keys removed.
+func withoutTags(tag reflect.StructTag, names ...string) reflect.StructTag {
+	var parts []string
+
+	for rest := strings.TrimSpace(string(tag)); rest != ""; rest = strings.TrimSpace(rest) {
+		name, value, ok := strings.Cut(rest, ":")
+		if !ok || value == "" || value[0] != '"' {
+			break
+		}
+
+		end := 1
+		for end < len(value) && value[end] != '"' {
+			if value[end] == '\\' {
+				end++
+			}
+			end++
+		}
+
+		if end >= len(value) {
+			break
+		}
+
+		if !slices.Contains(names, name) {
+			parts = append(parts, name+":"+value[:end+1])
+		}
+
+		rest = value[end+1:]
+	}
+
+	return reflect.StructTag(strings.Join(parts, " "))
+}
+
+// checkUnique reports the first element of the slice that duplicates a previous one.
+func checkUnique(slice reflect.Value) error {
+	seen := make(map[any]int, slice.Len())
+
+	for i := 0; i < slice.Len(); i++ {
+		v := reflect.Indirect(slice.Index(i))
+		if !v.IsValid() || !v.Comparable() {
+			continue
+		}
+
+		if j, ok := seen[v.Interface()]; ok {
+			return fmt.Errorf("%w: element %d duplicates element %d", ErrInvalidValue, i, j)
+		}
+
+		seen[v.Interface()] = i
+	}
+
+	return nil
+}
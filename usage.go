@@ -0,0 +1,62 @@
+package envset
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// Usage writes a table of the environment variables of the struct, their types, defaults,
+// constraints and descriptions from the `desc` tag, similar to flag.PrintDefaults.
+func Usage[T any](w io.Writer, structPtr *T, options ...Option) error {
+	return UsageType(w, reflect.TypeOf(structPtr).Elem(), options...)
+}
+
+// UsageType is Usage for a struct type held in reflect.Type,
+// e.g. built at runtime with reflect.StructOf.
+func UsageType(w io.Writer, structType reflect.Type, options ...Option) error {
+	fields, err := describeStruct(structType, docPlaceholders, options)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+
+	if _, err := io.WriteString(tw, "VARIABLE\tTYPE\tDEFAULT\tCONSTRAINTS\tDESCRIPTION\n"); err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		constraints := field.constraints()
+		if field.required() {
+			constraints = append([]string{"required"}, constraints...)
+		}
+
+		if _, err := io.WriteString(tw, strings.Join([]string{
+			field.key,
+			typeName(field.typ),
+			field.defaultValue(),
+			strings.Join(constraints, " "),
+			field.tag.Get(descTag),
+		}, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	// Rows without description are padded, trim the padding
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if _, err := io.WriteString(w, strings.TrimRight(line, " ")+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
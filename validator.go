@@ -0,0 +1,34 @@
+package envset
+
+import "reflect"
+
+// Validator is implemented by structs checking invariants too complex for tags.
+// Validate is called after the struct fields are populated, nested structs first.
+// Validate of an embedded struct is called once, as the promoted method of the embedding struct,
+// and not at all if the embedding struct declares its own Validate.
+type Validator interface {
+	Validate() error
+}
+
+var validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+
+// promotesValidator tells if the struct v has Validate method, while its field is embedded.
+// Validate of the embedded field is then either promoted, or shadowed by the struct own method,
+// so it is called once with the struct.
+func promotesValidator(v reflect.Value, field reflect.StructField) bool {
+	return field.Anonymous && reflect.PointerTo(v.Type()).Implements(validatorType)
+}
+
+// validate calls Validate method of the struct, if it has one,
+// annotating the error with the path of the struct.
+func validate(v reflect.Value, s scope) error {
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+
+	if validator, ok := v.Interface().(Validator); ok {
+		return s.wrapPath(validator.Validate())
+	}
+
+	return nil
+}